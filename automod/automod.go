@@ -9,6 +9,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -19,6 +20,7 @@ var (
 )
 
 type Plugin struct {
+	stopDecayLoop chan *sync.WaitGroup
 }
 
 func (p *Plugin) PluginInfo() *common.PluginInfo {
@@ -38,7 +40,9 @@ func RegisterPlugin() {
 		return
 	}
 
-	p := &Plugin{}
+	p := &Plugin{
+		stopDecayLoop: make(chan *sync.WaitGroup),
+	}
 	common.RegisterPlugin(p)
 }
 
@@ -93,6 +97,9 @@ const (
 	MaxLists        = 5
 	MaxListsPremium = 25
 
+	MaxDecayPolicies        = 10
+	MaxDecayPoliciesPremium = 50
+
 	MaxRuleParts = 20
 	MaxRulesets  = 10
 )
@@ -129,6 +136,14 @@ func GuildMaxLists(guildID int64) int {
 	return MaxLists
 }
 
+func GuildMaxDecayPolicies(guildID int64) int {
+	if isPremium, _ := premium.IsGuildPremium(guildID); isPremium {
+		return MaxDecayPoliciesPremium
+	}
+
+	return MaxDecayPolicies
+}
+
 func PrepareMessageForWordCheck(input string) string {
 	var out strings.Builder
 
@@ -167,3 +182,71 @@ func PrepareMessageForWordCheck(input string) string {
 
 	return out.String()
 }
+
+// NormalizeForSimilarity lowercases the input and strips everything but letters and numbers, to be used with Similarity
+func NormalizeForSimilarity(input string) string {
+	var out strings.Builder
+	for _, r := range input {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			out.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return out.String()
+}
+
+// maxSimilarityRunes is the max number of runes compared by Similarity, to keep it cheap on long messages
+const maxSimilarityRunes = 500
+
+// Similarity returns how similar a and b are in percent (0-100), based on the levenshtein distance between them
+func Similarity(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	if len(ra) > maxSimilarityRunes {
+		ra = ra[:maxSimilarityRunes]
+	}
+	if len(rb) > maxSimilarityRunes {
+		rb = rb[:maxSimilarityRunes]
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	if longest == 0 {
+		return 100
+	}
+
+	// levenshtein distance, only keeping 2 rows around
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return ((longest - prev[len(rb)]) * 100) / longest
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -12,11 +12,13 @@ import (
 	"github.com/jonas747/yagpdb/commands"
 	"github.com/jonas747/yagpdb/common"
 	"github.com/jonas747/yagpdb/common/pubsub"
+	"github.com/jonas747/yagpdb/common/scheduledevents2"
 	"github.com/sirupsen/logrus"
 	"github.com/volatiletech/null"
 	"github.com/volatiletech/sqlboiler/boil"
 	"github.com/volatiletech/sqlboiler/queries/qm"
 	"sort"
+	"time"
 )
 
 const PubSubEvtCleaCache = "automod_2_clear_guild_cache"
@@ -28,6 +30,13 @@ func (p *Plugin) BotInit() {
 	eventsystem.AddHandler(p.handleGuildMemberUpdate, eventsystem.EventGuildMemberUpdate)
 	eventsystem.AddHandler(p.handleMsgUpdate, eventsystem.EventMessageUpdate)
 	eventsystem.AddHandler(p.handleGuildMemberJoin, eventsystem.EventGuildMemberAdd)
+	eventsystem.AddHandler(p.handleReactionAdd, eventsystem.EventMessageReactionAdd)
+
+	go reactionTracker.runCleaner(time.Minute)
+	go joinTracker.runCleaner(time.Minute)
+	go raidHandledTracker.runCleaner(time.Minute)
+
+	scheduledevents2.RegisterHandler("automod_revert_verification_level", RevertVerificationLevelData{}, handleRevertVerificationLevel)
 
 	pubsub.AddHandler(PubSubEvtCleaCache, func(evt *pubsub.Event) {
 		gs := bot.State.Guild(true, evt.TargetGuildInt)
@@ -43,7 +52,60 @@ func (p *Plugin) BotInit() {
 }
 
 func (p *Plugin) handleMsgUpdate(evt *eventsystem.EventData) {
-	p.checkMessage(evt.MessageUpdate().Message)
+	msg := evt.MessageUpdate().Message
+	if msg.Author == nil && len(msg.Embeds) > 0 {
+		// link previews are added in a separate update that only contains the embeds
+		p.checkEmbedsUpdate(msg)
+		return
+	}
+
+	p.checkMessage(msg)
+}
+
+// checkEmbedsUpdate checks the embed triggers against a message update that only added embeds, the other triggers
+// were already checked when the message was created
+func (p *Plugin) checkEmbedsUpdate(update *discordgo.Message) {
+	cs := bot.State.Channel(true, update.ChannelID)
+	if cs == nil || cs.Guild == nil {
+		return
+	}
+
+	cs.Owner.RLock()
+	cached := cs.Message(false, update.ID)
+	if cached == nil || cached.Author == nil {
+		cs.Owner.RUnlock()
+		return
+	}
+
+	msg := &discordgo.Message{
+		ID:        update.ID,
+		ChannelID: update.ChannelID,
+		GuildID:   cs.Guild.ID,
+		Author:    cached.Author,
+		Content:   cached.Content,
+		Embeds:    update.Embeds,
+		WebhookID: cached.WebhookID,
+	}
+	cs.Owner.RUnlock()
+
+	if msg.Author.ID == common.BotUser.ID || msg.WebhookID != 0 || msg.Author.Discriminator == "0000" {
+		return
+	}
+
+	ms, err := bot.GetMember(msg.GuildID, msg.Author.ID)
+	if err != nil {
+		logrus.WithError(err).Debug("automod failed fetching member")
+		return
+	}
+
+	checkF := messageTriggerCheckFunc(ms, cs, msg)
+	p.CheckTriggers(nil, ms, msg, cs, func(trig *ParsedPart) (bool, error) {
+		if _, ok := trig.Part.(*EmbedDomainTrigger); !ok {
+			return false, nil
+		}
+
+		return checkF(trig)
+	})
 }
 
 func (p *Plugin) checkMessage(msg *discordgo.Message) bool {
@@ -63,8 +125,13 @@ func (p *Plugin) checkMessage(msg *discordgo.Message) bool {
 		return true
 	}
 
+	return !p.CheckTriggers(nil, ms, msg, cs, messageTriggerCheckFunc(ms, cs, msg))
+}
+
+// messageTriggerCheckFunc returns a trigger check function for use with CheckTriggers that checks message triggers against msg
+func messageTriggerCheckFunc(ms *dstate.MemberState, cs *dstate.ChannelState, msg *discordgo.Message) func(trig *ParsedPart) (activated bool, err error) {
 	stripped := ""
-	return !p.CheckTriggers(nil, ms, msg, cs, func(trig *ParsedPart) (activated bool, err error) {
+	return func(trig *ParsedPart) (activated bool, err error) {
 		if stripped == "" {
 			stripped = PrepareMessageForWordCheck(msg.Content)
 		}
@@ -75,7 +142,7 @@ func (p *Plugin) checkMessage(msg *discordgo.Message) bool {
 		}
 
 		return cast.CheckMessage(ms, cs, msg, stripped, trig.ParsedSettings)
-	})
+	}
 }
 
 func (p *Plugin) checkViolationTriggers(ctxData *TriggeredRuleData, violationName string) {
@@ -99,8 +166,8 @@ func (p *Plugin) checkViolationTriggers(ctxData *TriggeredRuleData, violationNam
 		return
 	}
 
-	// retrieve users violations
-	userViolations, err := models.AutomodViolations(qm.Where("guild_id = ? AND user_id = ? AND name = ?", ctxData.GS.ID, ctxData.MS.ID, violationName)).AllG(context.Background())
+	// retrieve users violations, decayed ones no longer count
+	userViolations, err := models.AutomodViolations(qm.Where("guild_id = ? AND user_id = ? AND name = ? AND decayed_at IS NULL", ctxData.GS.ID, ctxData.MS.ID, violationName)).AllG(context.Background())
 	if err != nil {
 		logrus.WithError(err).Error("automod failed retrieving user violations")
 		return
@@ -218,10 +285,86 @@ func (p *Plugin) handleGuildMemberJoin(evt *eventsystem.EventData) {
 	gs := bot.State.Guild(true, evtData.GuildID)
 	ms := dstate.MSFromDGoMember(gs, evtData.Member)
 
+	joinTracker.Add(evtData.GuildID, &trackedEvent{At: time.Now(), UserID: evtData.User.ID, Data: evtData.User})
+
 	p.checkJoin(ms)
 	p.checkUsername(ms)
 }
 
+// joinTracker keeps track of the members that joined recently, keyed by guild ID, Data is the *discordgo.User
+var joinTracker = newEventTracker(time.Hour)
+
+// reactionTracker keeps track of the reactions users have added recently, keyed by reactionTrackerKey
+var reactionTracker = newEventTracker(time.Hour)
+
+type reactionTrackerKey struct {
+	GuildID int64
+	UserID  int64
+}
+
+func (p *Plugin) handleReactionAdd(evt *eventsystem.EventData) {
+	ra := evt.MessageReactionAdd()
+	if ra.GuildID == 0 || ra.UserID == common.BotUser.ID {
+		return
+	}
+
+	cs := bot.State.Channel(true, ra.ChannelID)
+	if cs == nil || cs.Guild == nil {
+		return
+	}
+
+	// this runs for every reaction, so bail out before fetching the member if there's nothing to check
+	rulesets, err := p.FetchGuildRulesets(cs.Guild)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ra.GuildID).Error("automod: failed fetching triggers")
+		return
+	}
+
+	if !hasReactionTriggers(rulesets) {
+		return
+	}
+
+	ms, err := bot.GetMember(ra.GuildID, ra.UserID)
+	if err != nil {
+		logrus.WithError(err).Debug("automod failed fetching member")
+		return
+	}
+
+	reactionTracker.Add(reactionTrackerKey{GuildID: ra.GuildID, UserID: ra.UserID}, &trackedEvent{At: time.Now(), UserID: ra.UserID})
+
+	p.checkReaction(rulesets, ms, cs, ra.MessageReaction)
+}
+
+// hasReactionTriggers returns true if one of the enabled rulesets has a reaction trigger
+func hasReactionTriggers(rulesets []*ParsedRuleset) bool {
+	for _, rs := range rulesets {
+		if !rs.RSModel.Enabled {
+			continue
+		}
+
+		for _, rule := range rs.Rules {
+			for _, trig := range rule.Triggers {
+				if _, ok := trig.Part.(ReactionListener); ok {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *Plugin) checkReaction(rulesets []*ParsedRuleset, ms *dstate.MemberState, cs *dstate.ChannelState, reaction *discordgo.MessageReaction) {
+	p.CheckTriggersCtx(rulesets, &TriggeredRuleData{MS: ms, CS: cs, Reaction: reaction}, func(trig *ParsedPart) (activated bool, err error) {
+		cast, ok := trig.Part.(ReactionListener)
+		if !ok {
+			return false, nil
+		}
+
+		return cast.CheckReaction(ms, cs, reaction, trig.ParsedSettings)
+	})
+}
+
 func (p *Plugin) checkNickname(ms *dstate.MemberState) {
 	p.CheckTriggers(nil, ms, nil, nil, func(trig *ParsedPart) (activated bool, err error) {
 		cast, ok := trig.Part.(NicknameListener)
@@ -255,7 +398,22 @@ func (p *Plugin) checkJoin(ms *dstate.MemberState) {
 	})
 }
 
+// CheckReporter gets reported the result of every condition and trigger checked by CheckTriggersCtx, and the rules that would
+// have been triggered
+type CheckReporter interface {
+	ConditionChecked(ctxData *TriggeredRuleData, cond *ParsedPart, met bool, err error)
+	TriggerChecked(ctxData *TriggeredRuleData, trig *ParsedPart, activated bool, err error)
+	RuleTriggered(ctxData *TriggeredRuleData, rule *ParsedRule)
+}
+
 func (p *Plugin) CheckTriggers(rulesets []*ParsedRuleset, ms *dstate.MemberState, msg *discordgo.Message, cs *dstate.ChannelState, checkF func(trp *ParsedPart) (activated bool, err error)) bool {
+	return p.CheckTriggersCtx(rulesets, &TriggeredRuleData{MS: ms, CS: cs, Message: msg}, checkF)
+}
+
+// CheckTriggersCtx is the same as CheckTriggers but allows providing additional optional context data (such as a reaction) through baseCtx,
+// baseCtx.MS is required
+func (p *Plugin) CheckTriggersCtx(rulesets []*ParsedRuleset, baseCtx *TriggeredRuleData, checkF func(trp *ParsedPart) (activated bool, err error)) bool {
+	ms := baseCtx.MS
 	if rulesets == nil {
 		var err error
 		rulesets, err = p.FetchGuildRulesets(ms.Guild)
@@ -276,15 +434,10 @@ func (p *Plugin) CheckTriggers(rulesets []*ParsedRuleset, ms *dstate.MemberState
 			continue
 		}
 
-		ctxData := &TriggeredRuleData{
-			MS:      ms,
-			CS:      cs,
-			GS:      ms.Guild,
-			Plugin:  p,
-			Ruleset: rs,
-
-			Message: msg,
-		}
+		ctxData := baseCtx.Clone()
+		ctxData.GS = ms.Guild
+		ctxData.Plugin = p
+		ctxData.Ruleset = rs
 
 		// check if we match all conditions, starting with the ruleset conditions
 		if !p.CheckConditions(ctxData, rs.ParsedConditions) {
@@ -308,6 +461,10 @@ func (p *Plugin) CheckTriggers(rulesets []*ParsedRuleset, ms *dstate.MemberState
 			for _, trig := range rule.Triggers {
 
 				activated, err := checkF(trig)
+				if ctxData.Reporter != nil {
+					ctxData.Reporter.TriggerChecked(ctxData, trig, activated && err == nil, err)
+				}
+
 				if err != nil {
 					logrus.WithError(err).WithField("part_id", trig.RuleModel.ID).Error("failed checking trigger")
 					continue
@@ -335,8 +492,17 @@ func (p *Plugin) CheckTriggers(rulesets []*ParsedRuleset, ms *dstate.MemberState
 			ctxData.StrippedMessageContent = PrepareMessageForWordCheck(ctxData.Message.Content)
 		}
 
+		if ctxData.Reporter != nil {
+			// only reports what would happen, so no need to do it in the background
+			p.RulesetRulesTriggered(ctxData, true)
+			continue
+		}
+
 		go p.RulesetRulesTriggered(ctxData, true)
-		activatededRules = true
+		if !rs.RSModel.DryRun {
+			// rulesets in dry run mode never apply effects, so they shouldn't stop the message from being processed further either
+			activatededRules = true
+		}
 
 		logrus.WithField("guild", ctxData.GS.ID).Info("automod triggered ", len(triggeredRules), " rules")
 	}
@@ -382,6 +548,10 @@ func (p *Plugin) CheckConditions(ctxData *TriggeredRuleData, conditions []*Parse
 	// check if we match all conditions, starting with the ruleset conditions
 	for _, cond := range conditions {
 		met, err := cond.Part.(Condition).IsMet(ctxData, cond.ParsedSettings)
+		if ctxData.Reporter != nil {
+			ctxData.Reporter.ConditionChecked(ctxData, cond, met && err == nil, err)
+		}
+
 		if err != nil {
 			logrus.WithError(err).WithField("guild", ctxData.GS.ID).Error("failed checking if automod condition was met")
 			return false // assume the condition failed
@@ -396,20 +566,46 @@ func (p *Plugin) CheckConditions(ctxData *TriggeredRuleData, conditions []*Parse
 }
 
 func (p *Plugin) RulesetRulesTriggeredCondsPassed(ruleset *ParsedRuleset, triggeredRules []*ParsedRule, ctxData *TriggeredRuleData) {
+	if ctxData.Reporter != nil {
+		// only report the rules, without applying any effects, cooldowns or logging anything
+		for _, rule := range triggeredRules {
+			ctxData.Reporter.RuleTriggered(ctxData, rule)
+		}
+		return
+	}
 
 	loggedModels := make([]*models.AutomodTriggeredRule, len(triggeredRules))
 
+	dryRun := ruleset.RSModel.DryRun
+
 	// apply the effects
 	for i, rule := range triggeredRules {
 		ctxData.CurrentRule = rule
 
-		for _, effect := range rule.Effects {
-			go func(fx *ParsedPart, ctx *TriggeredRuleData) {
-				err := fx.Part.(Effect).Apply(ctx, fx.ParsedSettings)
-				if err != nil {
-					logrus.WithError(err).WithField("guild", ruleset.RSModel.GuildID).WithField("part", fx.Part.Name()).Error("failed applying automod effect")
-				}
-			}(effect, ctxData.Clone())
+		// skip the effects if the rule or user is on cooldown, to avoid hitting ratelimits and spamming during raids
+		onCooldown := false
+		if !dryRun {
+			canApply, err := checkRuleCooldowns(rule, ctxData.MS.ID)
+			if err != nil {
+				logrus.WithError(err).WithField("guild", ctxData.GS.ID).Error("automod: failed checking rule cooldowns")
+			}
+			onCooldown = !canApply && err == nil
+		}
+
+		effectSummaries := make([]string, len(rule.Effects))
+		for j, effect := range rule.Effects {
+			effectSummaries[j] = effect.Summary()
+
+			if dryRun || onCooldown {
+				// only log what would have happened
+				continue
+			}
+
+			go applyEffect(effect, ctxData.Clone())
+		}
+
+		if !dryRun && !onCooldown {
+			go p.applyBurstEffects(rule, ctxData.Clone())
 		}
 
 		// Log the rule activation
@@ -434,9 +630,16 @@ func (p *Plugin) RulesetRulesTriggeredCondsPassed(ruleset *ParsedRuleset, trigge
 		}
 
 		serializedExtraData := []byte("{}")
+		var extraData interface{}
 		if ctxData.Message != nil {
+			extraData = ctxData.Message
+		} else if ctxData.Reaction != nil {
+			extraData = ctxData.Reaction
+		}
+
+		if extraData != nil {
 			var err error
-			serializedExtraData, err = json.Marshal(ctxData.Message)
+			serializedExtraData, err = json.Marshal(extraData)
 			if err != nil {
 				logrus.WithError(err).Error("automod failed serializing extra data")
 				serializedExtraData = []byte("{}")
@@ -455,6 +658,9 @@ func (p *Plugin) RulesetRulesTriggeredCondsPassed(ruleset *ParsedRuleset, trigge
 			UserID:        ctxData.MS.ID,
 			UserName:      ctxData.MS.Username + "#" + ctxData.MS.StrDiscriminator(),
 			Extradata:     serializedExtraData,
+			DryRun:        dryRun,
+			Effects:       effectSummaries,
+			OnCooldown:    onCooldown,
 		}
 	}
 
@@ -479,6 +685,63 @@ func (p *Plugin) RulesetRulesTriggeredCondsPassed(ruleset *ParsedRuleset, trigge
 	}
 }
 
+func applyEffect(fx *ParsedPart, ctxData *TriggeredRuleData) {
+	err := fx.Part.(Effect).Apply(ctxData, fx.ParsedSettings)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ctxData.GS.ID).WithField("part", fx.Part.Name()).Error("failed applying automod effect")
+	}
+}
+
+// applyBurstEffects applies the effects of the rule on the other members affected by the activated trigger of the rule,
+// if the trigger is a BurstTrigger
+func (p *Plugin) applyBurstEffects(rule *ParsedRule, ctxData *TriggeredRuleData) {
+	var trig *ParsedPart
+	for _, v := range ctxData.ActivatedTriggers {
+		if v.RuleModel.RuleID == rule.Model.ID {
+			trig = v
+			break
+		}
+	}
+
+	if trig == nil {
+		return
+	}
+
+	burstTrigger, ok := trig.Part.(BurstTrigger)
+	if !ok {
+		return
+	}
+
+	members, err := burstTrigger.BurstMembers(ctxData, trig.ParsedSettings)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ctxData.GS.ID).Error("automod: failed retrieving burst members")
+		return
+	}
+
+	for _, id := range members {
+		if rule.Model.UserCooldown > 0 {
+			ok, err := setCooldown(RedisKeyRuleUserCooldown(rule.Model.ID, id), rule.Model.UserCooldown)
+			if err == nil && !ok {
+				continue
+			}
+		}
+
+		ms, err := bot.GetMember(ctxData.GS.ID, id)
+		if err != nil {
+			// most likely already left
+			continue
+		}
+
+		memberCtx := ctxData.Clone()
+		memberCtx.MS = ms
+
+		// apply them one after another instead of all at once to be a bit nicer to the api during raids
+		for _, effect := range rule.Effects {
+			applyEffect(effect, memberCtx)
+		}
+	}
+}
+
 type CacheKey int
 
 const (
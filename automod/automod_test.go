@@ -25,3 +25,71 @@ func TestPrepareMessageForWordCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		output int
+	}{
+		{a: "", b: "", output: 100},
+		{a: "abcd", b: "abcd", output: 100},
+		{a: "abcd", b: "abce", output: 75},
+		{a: "abcd", b: "", output: 0},
+		{a: "kitten", b: "sitting", output: 57},
+	}
+
+	for i, c := range cases {
+		t.Run("#"+strconv.Itoa(i), func(st *testing.T) {
+			result := Similarity(c.a, c.b)
+			if result != c.output {
+				st.Errorf("got: %d, expected: %d", result, c.output)
+			}
+		})
+	}
+}
+
+func TestNormalizeForSimilarity(t *testing.T) {
+	cases := []struct {
+		input  string
+		output string
+	}{
+		{input: "Free NITRO!!", output: "freenitro"},
+		{input: "f r e e", output: "free"},
+		{input: "", output: ""},
+	}
+
+	for i, c := range cases {
+		t.Run("#"+strconv.Itoa(i), func(st *testing.T) {
+			result := NormalizeForSimilarity(c.input)
+			if result != c.output {
+				st.Errorf("got: %q, expected: %q", result, c.output)
+			}
+		})
+	}
+}
+
+func TestAttachmentExtension(t *testing.T) {
+	cases := []struct {
+		input  string
+		output string
+	}{
+		{input: "image.png", output: "png"},
+		{input: "IMAGE.PNG", output: "png"},
+		{input: "archive.tar.gz", output: "gz"},
+		{input: "photo\u202egnp.exe", output: "exe"},
+		{input: "virus.exe. ", output: "exe"},
+		{input: "virus.exe...", output: "exe"},
+		{input: "README", output: ""},
+		{input: "README.", output: ""},
+		{input: "", output: ""},
+	}
+
+	for i, c := range cases {
+		t.Run("#"+strconv.Itoa(i), func(st *testing.T) {
+			result := AttachmentExtension(c.input)
+			if result != c.output {
+				st.Errorf("got: %q, expected: %q", result, c.output)
+			}
+		})
+	}
+}
@@ -47,7 +47,15 @@ func (p *Plugin) InitWeb() {
 	muxer.Handle(pat.Get(""), getIndexHandler)
 	muxer.Handle(pat.Get("/logs"), web.ControllerHandler(p.handleGetLogs, "automod_index"))
 
+	getViolationsHandler := web.ControllerHandler(p.handleGetViolations, "automod_index")
+	muxer.Handle(pat.Get("/violations"), getViolationsHandler)
+	muxer.Handle(pat.Post("/new_decay_policy"), web.ControllerPostHandler(p.handlePostAutomodCreateDecayPolicy, getViolationsHandler, CreateDecayPolicyData{}, "Created a new automod violation decay policy"))
+	muxer.Handle(pat.Post("/decay_policy/:policyID/delete"), web.ControllerPostHandler(p.handlePostAutomodDeleteDecayPolicy, getViolationsHandler, nil, "Deleted a automod violation decay policy"))
+
+	muxer.Handle(pat.Post("/test"), web.ControllerPostHandler(p.handlePostAutomodTestMessage, getIndexHandler, TestMessageData{}, ""))
+
 	muxer.Handle(pat.Post("/new_ruleset"), web.ControllerPostHandler(p.handlePostAutomodCreateRuleset, getIndexHandler, CreateRulesetData{}, "Created a new automod ruleset"))
+	muxer.Handle(pat.Post("/import"), web.ControllerPostHandler(p.handlePostAutomodImportRuleset, getIndexHandler, ImportRulesetData{}, "Imported a automod ruleset"))
 
 	// List handlers
 	muxer.Handle(pat.Post("/new_list"), web.ControllerPostHandler(p.handlePostAutomodCreateList, getIndexHandler, CreateListData{}, "Created a new automod list"))
@@ -65,6 +73,7 @@ func (p *Plugin) InitWeb() {
 	rulesetMuxer.Handle(pat.Get(""), getRulesetHandler)
 	rulesetMuxer.Handle(pat.Get("/"), getRulesetHandler)
 
+	rulesetMuxer.Handle(pat.Get("/export"), http.HandlerFunc(p.handleGetExportRuleset))
 	rulesetMuxer.Handle(pat.Post("/update"), web.ControllerPostHandler(p.handlePostAutomodUpdateRuleset, getRulesetHandler, UpdateRulesetData{}, "Updated a ruleset"))
 	rulesetMuxer.Handle(pat.Post("/delete"), web.ControllerPostHandler(p.handlePostAutomodDeleteRuleset, getIndexHandler, nil, "Deleted a ruleset"))
 
@@ -127,6 +136,111 @@ func (p *Plugin) handleGetLogs(w http.ResponseWriter, r *http.Request) (web.Temp
 	return p.handleGetAutomodIndex(w, r)
 }
 
+func (p *Plugin) handleGetViolations(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	g, tmpl := web.GetBaseCPContextData(r.Context())
+
+	tmpl["InViolations"] = true
+
+	policies, err := models.AutomodViolationDecayPolicies(qm.Where("guild_id=?", g.ID), qm.OrderBy("id asc")).AllG(r.Context())
+	if err != nil {
+		return tmpl, err
+	}
+	tmpl["AutomodDecayPolicies"] = policies
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("user"), 10, 64)
+	if userID != 0 {
+		scores, history, err := MemberViolationSummary(r.Context(), g.ID, userID, 100)
+		if err != nil {
+			return tmpl, err
+		}
+
+		tmpl["AutomodViolationsUser"] = userID
+		tmpl["AutomodViolationScores"] = scores
+		tmpl["AutomodViolationHistory"] = history
+	}
+
+	return p.handleGetAutomodIndex(w, r)
+}
+
+type CreateDecayPolicyData struct {
+	Name          string `valid:",1,50,trimspace"`
+	Kind          int    `valid:",0,1"`
+	IntervalHours int    `valid:",1,8760"`
+}
+
+func (p *Plugin) handlePostAutomodCreateDecayPolicy(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	g, tmpl := web.GetBaseCPContextData(r.Context())
+
+	totalPolicies, err := models.AutomodViolationDecayPolicies(qm.Where("guild_id = ? ", g.ID)).CountG(r.Context())
+	if err != nil {
+		return tmpl, err
+	}
+	if totalPolicies >= int64(GuildMaxDecayPolicies(g.ID)) {
+		tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Reached max number of decay policies, %d for normal servers and %d for premium servers", MaxDecayPolicies, MaxDecayPoliciesPremium)))
+		return tmpl, nil
+	}
+
+	data := r.Context().Value(common.ContextKeyParsedForm).(*CreateDecayPolicyData)
+
+	exists, err := models.AutomodViolationDecayPolicies(qm.Where("guild_id = ? AND name = ?", g.ID, data.Name)).ExistsG(r.Context())
+	if err != nil {
+		return tmpl, err
+	}
+	if exists {
+		tmpl.AddAlerts(web.ErrorAlert("There's already a decay policy for that violation name"))
+		return tmpl, nil
+	}
+
+	policy := &models.AutomodViolationDecayPolicy{
+		GuildID:       g.ID,
+		Name:          data.Name,
+		Kind:          data.Kind,
+		IntervalHours: data.IntervalHours,
+	}
+
+	err = policy.InsertG(r.Context(), boil.Infer())
+	return tmpl, err
+}
+
+func (p *Plugin) handlePostAutomodDeleteDecayPolicy(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	g, tmpl := web.GetBaseCPContextData(r.Context())
+
+	id := pat.Param(r, "policyID")
+	_, err := models.AutomodViolationDecayPolicies(qm.Where("guild_id=? AND id=?", g.ID, id)).DeleteAll(r.Context(), common.PQ)
+	return tmpl, err
+}
+
+type TestMessageData struct {
+	ChannelID int64 `valid:"channel,false"`
+	UserID    int64
+	Content   string `valid:",1,2000"`
+}
+
+func (p *Plugin) handlePostAutomodTestMessage(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	g, tmpl := web.GetBaseCPContextData(r.Context())
+
+	data := r.Context().Value(common.ContextKeyParsedForm).(*TestMessageData)
+	if data.UserID == 0 {
+		data.UserID = web.ContextUser(r.Context()).ID
+	}
+
+	results, err := botRestPostTestMessage(g.ID, &TestMessageRequest{
+		ChannelID: data.ChannelID,
+		UserID:    data.UserID,
+		Content:   data.Content,
+	})
+	if err != nil {
+		tmpl.AddAlerts(web.ErrorAlert("Failed testing the message: ", err.Error()))
+		return tmpl, nil
+	}
+
+	tmpl["AutomodTestResults"] = results
+	tmpl["AutomodTestData"] = data
+	tmpl["AutomodTestChannel"] = data.ChannelID
+
+	return tmpl, nil
+}
+
 type CreateRulesetData struct {
 	Name string `valid:",1,100"`
 }
@@ -156,6 +270,62 @@ func (p *Plugin) handlePostAutomodCreateRuleset(w http.ResponseWriter, r *http.R
 	return tmpl, err
 }
 
+func (p *Plugin) handleGetExportRuleset(w http.ResponseWriter, r *http.Request) {
+	g, _ := web.GetBaseCPContextData(r.Context())
+	ruleset := r.Context().Value(CtxKeyCurrentRuleset).(*models.AutomodRuleset)
+
+	export, err := ExportRuleset(r.Context(), g, ruleset)
+	if err != nil {
+		web.CtxLogger(r.Context()).WithError(err).Error("failed exporting automod ruleset")
+		http.Error(w, "Failed exporting ruleset", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"automod-ruleset-%d.json\"", ruleset.ID))
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(export)
+	if err != nil {
+		web.CtxLogger(r.Context()).WithError(err).Error("failed writing automod ruleset export")
+	}
+}
+
+type ImportRulesetData struct {
+	Data string `valid:",1,200000"`
+}
+
+func (p *Plugin) handlePostAutomodImportRuleset(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	g, tmpl := web.GetBaseCPContextData(r.Context())
+	data := r.Context().Value(common.ContextKeyParsedForm).(*ImportRulesetData)
+
+	export, err := ParseRulesetExport([]byte(data.Data))
+	if err != nil {
+		return tmpl, err
+	}
+
+	remap, roleMappings, channelMappings := web.ReadImportRemap(r.Form, g, &export.ExportedRefs)
+	if remap == nil {
+		// ask the user where to map the roles and channels that don't exist on this server
+		tmpl["AutomodImportData"] = data.Data
+		tmpl["AutomodImportName"] = export.Name
+		tmpl["AutomodImportRoles"] = roleMappings
+		tmpl["AutomodImportChannels"] = channelMappings
+		tmpl.AddAlerts(web.WarningAlert("The ruleset references roles or channels that does not exist on this server, select what to replace them with below to finish the import."))
+		return tmpl, nil
+	}
+
+	_, ok, err := ImportRuleset(r.Context(), g, tmpl, export, remap)
+	if err != nil || !ok {
+		return tmpl, err
+	}
+
+	pubsub.Publish(PubSubEvtCleaCache, g.ID, nil)
+	tmpl.AddAlerts(web.SucessAlert("Imported the ruleset ", export.Name))
+	return tmpl, nil
+}
+
 type CreateListData struct {
 	Name string `valid:",1,50"`
 }
@@ -300,6 +470,7 @@ func (p *Plugin) handlePostAutomodCreateRule(w http.ResponseWriter, r *http.Requ
 type UpdateRulesetData struct {
 	Name       string `valid:",1,50"`
 	Enabled    bool
+	DryRun     bool
 	Conditions []RuleRowData
 }
 
@@ -351,7 +522,8 @@ func (p *Plugin) handlePostAutomodUpdateRuleset(w http.ResponseWriter, r *http.R
 	// Update the ruleset model itself
 	ruleset.Name = data.Name
 	ruleset.Enabled = data.Enabled
-	_, err = ruleset.Update(r.Context(), tx, boil.Whitelist("name", "enabled"))
+	ruleset.DryRun = data.DryRun
+	_, err = ruleset.Update(r.Context(), tx, boil.Whitelist("name", "enabled", "dry_run"))
 	if err != nil {
 		tx.Rollback()
 		return tmpl, err
@@ -388,10 +560,13 @@ func (p *Plugin) handlePostAutomodDeleteRuleset(w http.ResponseWriter, r *http.R
 }
 
 type UpdateRuleData struct {
-	Name       string `valid:",1,50"`
-	Triggers   []RuleRowData
-	Conditions []RuleRowData
-	Effects    []RuleRowData
+	Name         string `valid:",1,50"`
+	Cooldown     int    `valid:",0,86400"`
+	UserCooldown int    `valid:",0,86400"`
+	BatchModlog  bool
+	Triggers     []RuleRowData
+	Conditions   []RuleRowData
+	Effects      []RuleRowData
 }
 
 type RuleRowData struct {
@@ -472,7 +647,10 @@ func (p *Plugin) handlePostAutomodUpdateRule(w http.ResponseWriter, r *http.Requ
 	}
 
 	currentRule.Name = data.Name
-	_, err = currentRule.Update(r.Context(), tx, boil.Whitelist("name"))
+	currentRule.Cooldown = data.Cooldown
+	currentRule.UserCooldown = data.UserCooldown
+	currentRule.BatchModlog = data.BatchModlog
+	_, err = currentRule.Update(r.Context(), tx, boil.Whitelist("name", "cooldown", "user_cooldown", "batch_modlog"))
 	if err != nil {
 		tx.Rollback()
 		return tmpl, err
@@ -2,8 +2,11 @@ package automod
 
 import (
 	"encoding/json"
+	"fmt"
+	"github.com/fatih/structs"
 	"github.com/jonas747/yagpdb/automod/models"
 	"github.com/pkg/errors"
+	"strings"
 )
 
 type ParsedRuleset struct {
@@ -31,6 +34,36 @@ type ParsedPart struct {
 	ParsedSettings interface{}
 }
 
+// Summary returns the name of the part followed by its non-empty user settings, e.g "Mute user (Duration (minutes): 10)"
+func (p *ParsedPart) Summary() string {
+	if p.ParsedSettings == nil {
+		return p.Part.Name()
+	}
+
+	values := structs.Map(p.ParsedSettings)
+
+	settings := make([]string, 0, len(values))
+	for _, def := range p.Part.UserSettings() {
+		v, ok := values[def.Key]
+		if !ok {
+			continue
+		}
+
+		str := fmt.Sprint(v)
+		if str == "" {
+			continue
+		}
+
+		settings = append(settings, def.Name+": "+str)
+	}
+
+	if len(settings) < 1 {
+		return p.Part.Name()
+	}
+
+	return p.Part.Name() + " (" + strings.Join(settings, ", ") + ")"
+}
+
 func ParseRuleset(rs *models.AutomodRuleset) (*ParsedRuleset, error) {
 	result := &ParsedRuleset{
 		RSModel: rs,
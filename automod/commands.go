@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/jonas747/dcmd"
 	"github.com/jonas747/discordgo"
+	"github.com/jonas747/dstate"
 	"github.com/jonas747/yagpdb/automod/models"
 	"github.com/jonas747/yagpdb/commands"
 	"github.com/volatiletech/sqlboiler/boil"
@@ -70,6 +71,8 @@ func (p *Plugin) AddCommands() {
 				onOff := "Enabled"
 				if !v.Enabled {
 					onOff = "Disabled"
+				} else if v.DryRun {
+					onOff = "Enabled (dry run)"
 				}
 
 				out.WriteString(fmt.Sprintf("%s: %s\n", v.Name, onOff))
@@ -122,9 +125,98 @@ func (p *Plugin) AddCommands() {
 			out.WriteString(fmt.Sprintf("Last 15%s triggered automod v2 rules (UTC):\n```\n", offsetStr))
 			for _, v := range entries {
 				t := v.CreatedAt.UTC().Format("02 Jan 2006 15:04")
-				out.WriteString(fmt.Sprintf("%-17s - %s - RS:%s - R:%s - T:%s\n", t, v.UserName, v.RulesetName, v.RuleName, RulePartMap[v.TriggerTypeid].Name()))
+				dryRunStr := ""
+				if v.DryRun {
+					dryRunStr = " (dry run)"
+				} else if v.OnCooldown {
+					dryRunStr = " (cooldown)"
+				}
+
+				out.WriteString(fmt.Sprintf("%-17s - %s - RS:%s - R:%s - T:%s%s\n", t, v.UserName, v.RulesetName, v.RuleName, RulePartMap[v.TriggerTypeid].Name(), dryRunStr))
 			}
-			out.WriteString("``` `RS` = ruleset, `R` = rule, `T` = trigger")
+			out.WriteString("``` `RS` = ruleset, `R` = rule, `T` = trigger, `(dry run)`/`(cooldown)` = no effects were applied")
+
+			return out.String(), nil
+		},
+	}
+
+	cmdTest := &commands.YAGCommand{
+		Name:         "Test",
+		CmdCategory:  commands.CategoryModeration,
+		Description:  "Tests a message against all rulesets without applying any effects, showing which triggers and conditions passed",
+		RequiredArgs: 1,
+		Arguments: []*dcmd.ArgDef{
+			&dcmd.ArgDef{Name: "message", Type: dcmd.String},
+		},
+		ArgSwitches: []*dcmd.ArgDef{
+			&dcmd.ArgDef{Switch: "user", Help: "Member to test as, defaults to you", Type: &commands.MemberArg{}},
+			&dcmd.ArgDef{Switch: "channel", Help: "Channel to test in, defaults to this one", Type: dcmd.Channel},
+		},
+		RequireDiscordPerms: []int64{discordgo.PermissionManageServer, discordgo.PermissionAdministrator, discordgo.PermissionBanMembers},
+		RunFunc: func(data *dcmd.Data) (interface{}, error) {
+			ms := commands.ContextMS(data.Context())
+			if data.Switch("user").Value != nil {
+				ms = data.Switch("user").Value.(*dstate.MemberState)
+			}
+
+			cs := data.CS
+			if data.Switch("channel").Value != nil {
+				cs = data.Switch("channel").Value.(*dstate.ChannelState)
+			}
+
+			results, err := p.TestMessage(ms, cs, data.Args[0].Str())
+			if err != nil {
+				return nil, err
+			}
+
+			return FormatTestResults(results), nil
+		},
+	}
+
+	cmdViolations := &commands.YAGCommand{
+		Name:         "Violations",
+		Aliases:      []string{"violation", "v"},
+		CmdCategory:  commands.CategoryModeration,
+		Description:  "Shows the live violation score of a user along with their most recent violations, including decayed ones",
+		RequiredArgs: 1,
+		Arguments: []*dcmd.ArgDef{
+			&dcmd.ArgDef{Name: "user", Type: dcmd.UserID},
+		},
+		RequireDiscordPerms: []int64{discordgo.PermissionManageServer, discordgo.PermissionAdministrator, discordgo.PermissionBanMembers},
+		RunFunc: func(data *dcmd.Data) (interface{}, error) {
+			userID := data.Args[0].Int64()
+
+			scores, history, err := MemberViolationSummary(data.Context(), data.GS.ID, userID, 15)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(scores) < 1 {
+				return "That user has no violations", nil
+			}
+
+			out := &strings.Builder{}
+			out.WriteString("Violation scores:\n```\n")
+			for _, v := range scores {
+				policyStr := "no decay"
+				if v.Policy != nil {
+					policyStr = fmt.Sprintf("%s after %dh", DecayPolicyKind(v.Policy.Kind), v.Policy.IntervalHours)
+				}
+
+				out.WriteString(fmt.Sprintf("%-20s - %d active, %d decayed (%s)\n", v.Name, v.Active, v.Decayed, policyStr))
+			}
+			out.WriteString("```\nLast 15 violations (UTC):\n```\n")
+
+			for _, v := range history {
+				t := v.CreatedAt.UTC().Format("02 Jan 2006 15:04")
+				decayedStr := "active"
+				if v.DecayedAt.Valid {
+					decayedStr = "decayed " + v.DecayedAt.Time.UTC().Format("02 Jan 2006 15:04")
+				}
+
+				out.WriteString(fmt.Sprintf("%-17s - %s - %s\n", t, v.Name, decayedStr))
+			}
+			out.WriteString("```")
 
 			return out.String(), nil
 		},
@@ -136,4 +228,6 @@ func (p *Plugin) AddCommands() {
 	container.AddCommand(cmdViewRulesets, cmdViewRulesets.GetTrigger())
 	container.AddCommand(cmdToggleRuleset, cmdToggleRuleset.GetTrigger())
 	container.AddCommand(cmdLogs, cmdLogs.GetTrigger())
+	container.AddCommand(cmdTest, cmdTest.GetTrigger())
+	container.AddCommand(cmdViolations, cmdViolations.GetTrigger())
 }
@@ -11,6 +11,8 @@ CREATE TABLE IF NOT EXISTS automod_rulesets (
 
 CREATE INDEX IF NOT EXISTS automod_rulesets_guild_idx ON automod_rulesets(guild_id);
 
+ALTER TABLE automod_rulesets ADD COLUMN IF NOT EXISTS dry_run BOOLEAN NOT NULL DEFAULT false;
+
 
 CREATE TABLE IF NOT EXISTS automod_rules (
 	id BIGSERIAL PRIMARY KEY,
@@ -22,6 +24,10 @@ CREATE TABLE IF NOT EXISTS automod_rules (
 
 CREATE INDEX IF NOT EXISTS automod_rules_guild_idx ON automod_rules(guild_id);
 
+ALTER TABLE automod_rules ADD COLUMN IF NOT EXISTS cooldown INT NOT NULL DEFAULT 0;
+ALTER TABLE automod_rules ADD COLUMN IF NOT EXISTS user_cooldown INT NOT NULL DEFAULT 0;
+ALTER TABLE automod_rules ADD COLUMN IF NOT EXISTS batch_modlog BOOLEAN NOT NULL DEFAULT false;
+
 CREATE TABLE IF NOT EXISTS automod_rule_data (
 	id BIGSERIAL PRIMARY KEY,
 	guild_id BIGINT NOT NULL,
@@ -60,6 +66,19 @@ CREATE TABLE IF NOT EXISTS automod_violations (
 CREATE INDEX IF NOT EXISTS automod_violations_guild_idx ON automod_violations(guild_id);
 CREATE INDEX IF NOT EXISTS automod_violations_user_idx ON automod_violations(user_id);
 
+ALTER TABLE automod_violations ADD COLUMN IF NOT EXISTS decayed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS automod_violation_decay_policies (
+	id BIGSERIAL PRIMARY KEY,
+	guild_id BIGINT NOT NULL,
+
+	name TEXT NOT NULL, -- the violation name this policy applies to
+	kind INT NOT NULL,
+	interval_hours INT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS automod_violation_decay_policies_guild_name_idx ON automod_violation_decay_policies(guild_id, name);
+
 CREATE TABLE IF NOT EXISTS automod_lists (
 	id BIGSERIAL PRIMARY KEY,
 	guild_id BIGINT NOT NULL,
@@ -92,4 +111,8 @@ CREATE TABLE IF NOT EXISTS automod_triggered_rules (
 );
 
 CREATE INDEX IF NOT EXISTS automod_triggered_rules_guild_idx ON automod_triggered_rules(guild_id);
+
+ALTER TABLE automod_triggered_rules ADD COLUMN IF NOT EXISTS dry_run BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE automod_triggered_rules ADD COLUMN IF NOT EXISTS effects TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE automod_triggered_rules ADD COLUMN IF NOT EXISTS on_cooldown BOOLEAN NOT NULL DEFAULT false;
 `
@@ -0,0 +1,183 @@
+package automod
+
+import (
+	"context"
+	"github.com/jonas747/yagpdb/automod/models"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/common/backgroundworkers"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"sort"
+	"sync"
+	"time"
+)
+
+type DecayPolicyKind int
+
+const (
+	// Each violation expires after the interval
+	DecayPolicyExpire DecayPolicyKind = iota
+
+	// One violation is forgiven for every interval without new violations
+	DecayPolicyForgive
+)
+
+func (k DecayPolicyKind) String() string {
+	switch k {
+	case DecayPolicyExpire:
+		return "Expire"
+	case DecayPolicyForgive:
+		return "Forgive"
+	}
+
+	return "Unknown"
+}
+
+var _ backgroundworkers.BackgroundWorkerPlugin = (*Plugin)(nil)
+
+func (p *Plugin) RunBackgroundWorker() {
+	p.runDecayLoop()
+}
+
+func (p *Plugin) StopBackgroundWorker(wg *sync.WaitGroup) {
+	p.stopDecayLoop <- wg
+}
+
+func (p *Plugin) runDecayLoop() {
+	ticker := time.NewTicker(time.Minute * 5)
+
+	for {
+		select {
+		case <-ticker.C:
+			started := time.Now()
+			n, err := runViolationDecay(context.Background())
+			if err != nil {
+				logrus.WithError(err).Error("[automod] failed running violation decay")
+			} else if n > 0 {
+				logrus.Infof("[automod] decayed %d violations in %s", n, time.Since(started))
+			}
+		case wg := <-p.stopDecayLoop:
+			wg.Done()
+			return
+		}
+	}
+}
+
+// runViolationDecay applies all the decay policies, returning the number of violations that decayed
+func runViolationDecay(ctx context.Context) (int64, error) {
+	policies, err := models.AutomodViolationDecayPolicies().AllG(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := int64(0)
+	for _, v := range policies {
+		n, err := applyDecayPolicy(ctx, v, time.Now())
+		if err != nil {
+			logrus.WithError(err).WithField("guild", v.GuildID).Error("[automod] failed applying violation decay policy")
+			continue
+		}
+
+		total += n
+	}
+
+	return total, nil
+}
+
+func applyDecayPolicy(ctx context.Context, policy *models.AutomodViolationDecayPolicy, now time.Time) (int64, error) {
+	cutoff := now.Add(-time.Hour * time.Duration(policy.IntervalHours))
+
+	switch DecayPolicyKind(policy.Kind) {
+	case DecayPolicyExpire:
+		res, err := common.PQ.ExecContext(ctx, `UPDATE automod_violations SET decayed_at = $1
+WHERE guild_id = $2 AND name = $3 AND decayed_at IS NULL AND created_at < $4`, now, policy.GuildID, policy.Name, cutoff)
+		if err != nil {
+			return 0, err
+		}
+
+		return res.RowsAffected()
+
+	case DecayPolicyForgive:
+		// forgive the oldest active violation of every user that has not had a violation added or forgiven within the interval
+		res, err := common.PQ.ExecContext(ctx, `UPDATE automod_violations SET decayed_at = $1 WHERE id IN (
+	SELECT DISTINCT ON (v.user_id) v.id FROM automod_violations v
+	WHERE v.guild_id = $2 AND v.name = $3 AND v.decayed_at IS NULL
+	AND NOT EXISTS (
+		SELECT 1 FROM automod_violations recent
+		WHERE recent.guild_id = v.guild_id AND recent.user_id = v.user_id AND recent.name = v.name
+		AND (recent.created_at > $4 OR recent.decayed_at > $4)
+	)
+	ORDER BY v.user_id, v.created_at ASC
+)`, now, policy.GuildID, policy.Name, cutoff)
+		if err != nil {
+			return 0, err
+		}
+
+		return res.RowsAffected()
+	}
+
+	return 0, nil
+}
+
+// ViolationScore is the live score of a single violation name for a member
+type ViolationScore struct {
+	Name    string
+	Active  int
+	Decayed int
+
+	// nil if there's no decay policy for this violation name
+	Policy *models.AutomodViolationDecayPolicy
+}
+
+// MemberViolationSummary returns the scores for every violation name the member has along with the history of their
+// most recent violations (newest first), including decayed ones
+func MemberViolationSummary(ctx context.Context, guildID, userID int64, historyLimit int) ([]*ViolationScore, []*models.AutomodViolation, error) {
+	violations, err := models.AutomodViolations(qm.Where("guild_id = ? AND user_id = ?", guildID, userID), qm.OrderBy("created_at desc")).AllG(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policies, err := models.AutomodViolationDecayPolicies(qm.Where("guild_id = ?", guildID)).AllG(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scores := make([]*ViolationScore, 0)
+	for _, v := range violations {
+		var score *ViolationScore
+		for _, s := range scores {
+			if s.Name == v.Name {
+				score = s
+				break
+			}
+		}
+
+		if score == nil {
+			score = &ViolationScore{Name: v.Name}
+			for _, p := range policies {
+				if p.Name == v.Name {
+					score.Policy = p
+					break
+				}
+			}
+
+			scores = append(scores, score)
+		}
+
+		if v.DecayedAt.Valid {
+			score.Decayed++
+		} else {
+			score.Active++
+		}
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].Active > scores[j].Active
+	})
+
+	if len(violations) > historyLimit {
+		violations = violations[:historyLimit]
+	}
+
+	return scores, violations, nil
+}
@@ -2,16 +2,24 @@ package automod
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/jonas747/discordgo"
 	"github.com/jonas747/dstate"
 	"github.com/jonas747/yagpdb/automod/models"
 	"github.com/jonas747/yagpdb/bot"
 	"github.com/jonas747/yagpdb/common"
 	"github.com/jonas747/yagpdb/common/scheduledevents2"
+	seventsmodels "github.com/jonas747/yagpdb/common/scheduledevents2/models"
+	"github.com/jonas747/yagpdb/common/templates"
+	"github.com/jonas747/yagpdb/customcommands"
+	ccmodels "github.com/jonas747/yagpdb/customcommands/models"
 	"github.com/jonas747/yagpdb/moderation"
 	"github.com/sirupsen/logrus"
 	"github.com/volatiletech/null"
 	"github.com/volatiletech/sqlboiler/boil"
 	"github.com/volatiletech/sqlboiler/queries/qm"
+	"strings"
 	"time"
 )
 
@@ -297,7 +305,15 @@ func (kick *KickUserEffect) Apply(ctxData *TriggeredRuleData, settings interface
 		reason += ctxData.ConstructReason(true)
 	}
 
-	err := moderation.KickUser(nil, ctxData.GS.ID, cID, common.BotUser, reason, ctxData.MS.DGoUser())
+	config, batched, err := ctxData.moderationConfig()
+	if err != nil {
+		return err
+	}
+
+	err = moderation.KickUser(config, ctxData.GS.ID, cID, common.BotUser, reason, ctxData.MS.DGoUser())
+	if err == nil && batched {
+		ctxData.addModlogBatchEntry(moderation.MAKick, ctxData.MS.DGoUser())
+	}
 	return err
 }
 
@@ -363,8 +379,20 @@ func (ban *BanUserEffect) Apply(ctxData *TriggeredRuleData, settings interface{}
 		reason += ctxData.ConstructReason(true)
 	}
 
+	config, batched, err := ctxData.moderationConfig()
+	if err != nil {
+		return err
+	}
+
 	duration := time.Duration(settingsCast.Duration) * time.Minute
-	err := moderation.BanUserWithDuration(nil, ctxData.GS.ID, cID, common.BotUser, reason, ctxData.MS.DGoUser(), duration)
+	err = moderation.BanUserWithDuration(config, ctxData.GS.ID, cID, common.BotUser, reason, ctxData.MS.DGoUser(), duration)
+	if err == nil && batched {
+		action := moderation.MABanned
+		if duration > 0 {
+			action.Prefix += " (" + common.HumanizeDuration(common.DurationPrecisionMinutes, duration) + ")"
+		}
+		ctxData.addModlogBatchEntry(action, ctxData.MS.DGoUser())
+	}
 	return err
 }
 
@@ -432,7 +460,15 @@ func (mute *MuteUserEffect) Apply(ctxData *TriggeredRuleData, settings interface
 		reason += ctxData.ConstructReason(true)
 	}
 
-	err := moderation.MuteUnmuteUser(nil, true, ctxData.GS.ID, cID, common.BotUser, reason, ctxData.MS, settingsCast.Duration)
+	config, batched, err := ctxData.moderationConfig()
+	if err != nil {
+		return err
+	}
+
+	err = moderation.MuteUnmuteUser(config, true, ctxData.GS.ID, cID, common.BotUser, reason, ctxData.MS, settingsCast.Duration)
+	if err == nil && batched {
+		ctxData.addModlogBatchEntry(moderation.MAMute, ctxData.MS.DGoUser())
+	}
 	return err
 }
 
@@ -491,7 +527,15 @@ func (warn *WarnUserEffect) Apply(ctxData *TriggeredRuleData, settings interface
 		reason += ctxData.ConstructReason(true)
 	}
 
-	err := moderation.WarnUser(nil, ctxData.GS.ID, cID, common.BotUser, ctxData.MS.DGoUser(), reason)
+	config, batched, err := ctxData.moderationConfig()
+	if err != nil {
+		return err
+	}
+
+	err = moderation.WarnUser(config, ctxData.GS.ID, cID, common.BotUser, ctxData.MS.DGoUser(), reason)
+	if err == nil && batched && config.WarnSendToModlog {
+		ctxData.addModlogBatchEntry(moderation.MAWarned, ctxData.MS.DGoUser())
+	}
 	return err
 }
 
@@ -664,3 +708,427 @@ func (gf *GiveRoleEffect) Apply(ctxData *TriggeredRuleData, settings interface{}
 
 	return nil
 }
+
+/////////////////////////////////////////////////////////////
+
+type RemoveReactionEffect struct{}
+
+func (rr *RemoveReactionEffect) Kind() RulePartType {
+	return RulePartEffect
+}
+
+func (rr *RemoveReactionEffect) DataType() interface{} {
+	return nil
+}
+
+func (rr *RemoveReactionEffect) UserSettings() []*SettingDef {
+	return []*SettingDef{}
+}
+
+func (rr *RemoveReactionEffect) Name() (name string) {
+	return "Remove reaction"
+}
+
+func (rr *RemoveReactionEffect) Description() (description string) {
+	return "Removes the reaction that triggered the rule, only works with reaction triggers"
+}
+
+func (rr *RemoveReactionEffect) Apply(ctxData *TriggeredRuleData, settings interface{}) error {
+	if ctxData.Reaction == nil {
+		return nil // no reaction to remove
+	}
+
+	r := ctxData.Reaction
+	err := common.BotSession.MessageReactionRemove(r.ChannelID, r.MessageID, r.Emoji.APIName(), r.UserID)
+	return err
+}
+
+func (rr *RemoveReactionEffect) MergeDuplicates(data []interface{}) interface{} {
+	return nil // no user data
+}
+
+/////////////////////////////////////////////////////////////
+
+type VerificationLockdownEffectData struct {
+	Level    int
+	Duration int
+}
+
+type VerificationLockdownEffect struct{}
+
+func (vl *VerificationLockdownEffect) Kind() RulePartType {
+	return RulePartEffect
+}
+
+func (vl *VerificationLockdownEffect) DataType() interface{} {
+	return &VerificationLockdownEffectData{}
+}
+
+func (vl *VerificationLockdownEffect) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name:    "Verification level (1: email, 2: 5 min account age, 3: 10 min member, 4: phone)",
+			Key:     "Level",
+			Kind:    SettingTypeInt,
+			Min:     1,
+			Max:     4,
+			Default: 3,
+		},
+		&SettingDef{
+			Name:    "Duration (minutes)",
+			Key:     "Duration",
+			Kind:    SettingTypeInt,
+			Min:     1,
+			Max:     10080,
+			Default: 30,
+		},
+	}
+}
+
+func (vl *VerificationLockdownEffect) Name() (name string) {
+	return "Raise verification level"
+}
+
+func (vl *VerificationLockdownEffect) Description() (description string) {
+	return "Raises the server verification level for the specified duration, after which it's set back to what it was before. Meant to be used with the raid trigger."
+}
+
+func (vl *VerificationLockdownEffect) Apply(ctxData *TriggeredRuleData, settings interface{}) error {
+	settingsCast := settings.(*VerificationLockdownEffectData)
+
+	ctxData.GS.RLock()
+	currentLevel := int(ctxData.GS.Guild.VerificationLevel)
+	ctxData.GS.RUnlock()
+
+	revertAt := time.Now().Add(time.Minute * time.Duration(settingsCast.Duration))
+
+	// if a lockdown is already in place, keep the level from before the first lockdown and just extend it
+	existing, err := seventsmodels.ScheduledEvents(qm.Where("event_name='automod_revert_verification_level' AND guild_id = ? AND processed = false", ctxData.GS.ID)).All(context.Background(), common.PQ)
+	if err != nil {
+		return err
+	}
+
+	if len(existing) > 0 {
+		var data RevertVerificationLevelData
+		err = json.Unmarshal(existing[0].Data, &data)
+		if err != nil {
+			return err
+		}
+
+		if currentLevel != data.LockdownLevel {
+			// someone changed it manually in the meantime, leave it alone
+			_, err = existing.DeleteAll(context.Background(), common.PQ)
+			return err
+		}
+
+		if existing[0].TriggersAt.After(revertAt) {
+			return nil
+		}
+
+		_, err = existing.DeleteAll(context.Background(), common.PQ)
+		if err != nil {
+			return err
+		}
+
+		return scheduledevents2.ScheduleEvent("automod_revert_verification_level", ctxData.GS.ID, revertAt, &data)
+	}
+
+	if currentLevel >= settingsCast.Level {
+		return nil // already at or above the requested level
+	}
+
+	newLevel := discordgo.VerificationLevel(settingsCast.Level)
+	_, err = common.BotSession.GuildEdit(ctxData.GS.ID, discordgo.GuildParams{
+		VerificationLevel: &newLevel,
+	})
+	if err != nil {
+		return err
+	}
+
+	return scheduledevents2.ScheduleEvent("automod_revert_verification_level", ctxData.GS.ID, revertAt, &RevertVerificationLevelData{
+		Level:         currentLevel,
+		LockdownLevel: settingsCast.Level,
+	})
+}
+
+func (vl *VerificationLockdownEffect) MergeDuplicates(data []interface{}) interface{} {
+	return data[0] // no point in having duplicates of this
+}
+
+type RevertVerificationLevelData struct {
+	// The level to revert to
+	Level int `json:"level"`
+
+	// The level set by the lockdown, if the level was changed manually since then it's not reverted
+	LockdownLevel int `json:"lockdown_level"`
+}
+
+func handleRevertVerificationLevel(evt *seventsmodels.ScheduledEvent, data interface{}) (retry bool, err error) {
+	dataCast := data.(*RevertVerificationLevelData)
+
+	gs := bot.State.Guild(true, evt.GuildID)
+	if gs == nil {
+		return false, nil
+	}
+
+	gs.RLock()
+	currentLevel := int(gs.Guild.VerificationLevel)
+	gs.RUnlock()
+
+	if currentLevel != dataCast.LockdownLevel {
+		return false, nil
+	}
+
+	level := discordgo.VerificationLevel(dataCast.Level)
+	_, err = common.BotSession.GuildEdit(evt.GuildID, discordgo.GuildParams{
+		VerificationLevel: &level,
+	})
+
+	return scheduledevents2.CheckDiscordErrRetry(err), err
+}
+
+/////////////////////////////////////////////////////////////
+
+// templateContext creates a template context with information about what triggered the rule exposed to it
+func (t *TriggeredRuleData) templateContext(cs *dstate.ChannelState) (*templates.Context, error) {
+	tmplCtx := templates.NewContext(t.GS, cs, t.MS)
+
+	if t.CurrentRule != nil {
+		tmplCtx.Data["RuleName"] = t.CurrentRule.Model.Name
+	}
+	tmplCtx.Data["RulesetName"] = t.Ruleset.RSModel.Name
+
+	triggerNames := make([]string, 0, len(t.ActivatedTriggers))
+	for _, v := range t.ActivatedTriggers {
+		triggerNames = append(triggerNames, v.Part.Name())
+	}
+	tmplCtx.Data["TriggerName"] = strings.Join(triggerNames, ", ")
+
+	matchedContent := ""
+	if t.Message != nil {
+		matchedContent = t.Message.Content
+		tmplCtx.Msg = t.Message
+	} else if t.Reaction != nil {
+		matchedContent = t.Reaction.Emoji.APIName()
+	}
+	tmplCtx.Data["MatchedContent"] = matchedContent
+
+	violations, err := models.AutomodViolations(qm.Where("guild_id = ? AND user_id = ? AND decayed_at IS NULL", t.GS.ID, t.MS.ID)).CountG(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	tmplCtx.Data["Violations"] = violations
+
+	return tmplCtx, nil
+}
+
+const templateDataHelp = "Available template data: {{.RuleName}}, {{.RulesetName}}, {{.TriggerName}}, {{.MatchedContent}} and {{.Violations}}"
+
+type SendMessageEffectData struct {
+	Channel int64  `valid:"channel,true"`
+	Message string `valid:"template,2000"`
+}
+
+type SendMessageEffect struct{}
+
+func (sm *SendMessageEffect) Kind() RulePartType {
+	return RulePartEffect
+}
+
+func (sm *SendMessageEffect) DataType() interface{} {
+	return &SendMessageEffectData{}
+}
+
+func (sm *SendMessageEffect) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name: "Channel",
+			Key:  "Channel",
+			Kind: SettingTypeChannel,
+		},
+		&SettingDef{
+			Name: "Message",
+			Key:  "Message",
+			Kind: SettingTypeString,
+			Min:  1,
+			Max:  2000,
+		},
+	}
+}
+
+func (sm *SendMessageEffect) Name() (name string) {
+	return "Send message"
+}
+
+func (sm *SendMessageEffect) Description() (description string) {
+	return "Sends a templated message in the channel it was triggered in, or the specified channel. " + templateDataHelp
+}
+
+func (sm *SendMessageEffect) Apply(ctxData *TriggeredRuleData, settings interface{}) error {
+	settingsCast := settings.(*SendMessageEffectData)
+
+	cs := ctxData.CS
+	if settingsCast.Channel != 0 {
+		cs = ctxData.GS.Channel(true, settingsCast.Channel)
+	}
+
+	if cs == nil {
+		return nil // not triggered in a channel, or the channel was deleted
+	}
+
+	tmplCtx, err := ctxData.templateContext(cs)
+	if err != nil {
+		return err
+	}
+
+	msg, err := tmplCtx.Execute(settingsCast.Message)
+	if err != nil {
+		return err
+	}
+
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return nil
+	}
+
+	m, err := common.BotSession.ChannelMessageSend(cs.ID, msg)
+	if err == nil && tmplCtx.DelResponse {
+		templates.MaybeScheduledDeleteMessage(ctxData.GS.ID, cs.ID, m.ID, tmplCtx.DelResponseDelay)
+	}
+
+	return err
+}
+
+/////////////////////////////////////////////////////////////
+
+type DMUserEffectData struct {
+	Message string `valid:"template,2000"`
+}
+
+type DMUserEffect struct{}
+
+func (dm *DMUserEffect) Kind() RulePartType {
+	return RulePartEffect
+}
+
+func (dm *DMUserEffect) DataType() interface{} {
+	return &DMUserEffectData{}
+}
+
+func (dm *DMUserEffect) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name: "Message",
+			Key:  "Message",
+			Kind: SettingTypeString,
+			Min:  1,
+			Max:  2000,
+		},
+	}
+}
+
+func (dm *DMUserEffect) Name() (name string) {
+	return "Send DM"
+}
+
+func (dm *DMUserEffect) Description() (description string) {
+	return "Sends the user a templated direct message, for example explaining why they were punished. " + templateDataHelp
+}
+
+func (dm *DMUserEffect) Apply(ctxData *TriggeredRuleData, settings interface{}) error {
+	settingsCast := settings.(*DMUserEffectData)
+
+	tmplCtx, err := ctxData.templateContext(ctxData.CS)
+	if err != nil {
+		return err
+	}
+
+	msg, err := tmplCtx.Execute(settingsCast.Message)
+	if err != nil {
+		return err
+	}
+
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return nil
+	}
+
+	// the user may have dm's disabled, nothing we can do about that so ignore the error
+	go bot.SendDM(ctxData.MS.ID, "**"+bot.GuildName(ctxData.GS.ID)+":** "+msg)
+	return nil
+}
+
+func (dm *DMUserEffect) MergeDuplicates(data []interface{}) interface{} {
+	return data[0] // don't spam the user with several dm's
+}
+
+/////////////////////////////////////////////////////////////
+
+type RunCustomCommandEffectData struct {
+	CommandID int64
+	Channel   int64 `valid:"channel,true"`
+}
+
+type RunCustomCommandEffect struct{}
+
+func (rc *RunCustomCommandEffect) Kind() RulePartType {
+	return RulePartEffect
+}
+
+func (rc *RunCustomCommandEffect) DataType() interface{} {
+	return &RunCustomCommandEffectData{}
+}
+
+func (rc *RunCustomCommandEffect) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name: "Custom command ID",
+			Key:  "CommandID",
+			Kind: SettingTypeInt,
+			Min:  1,
+			Max:  1000000,
+		},
+		&SettingDef{
+			Name: "Channel",
+			Key:  "Channel",
+			Kind: SettingTypeChannel,
+		},
+	}
+}
+
+func (rc *RunCustomCommandEffect) Name() (name string) {
+	return "Run custom command"
+}
+
+func (rc *RunCustomCommandEffect) Description() (description string) {
+	return "Runs the specified custom command, regardless of its trigger. " + templateDataHelp
+}
+
+func (rc *RunCustomCommandEffect) Apply(ctxData *TriggeredRuleData, settings interface{}) error {
+	settingsCast := settings.(*RunCustomCommandEffectData)
+
+	cs := ctxData.CS
+	if settingsCast.Channel != 0 {
+		cs = ctxData.GS.Channel(true, settingsCast.Channel)
+	}
+
+	if cs == nil {
+		return nil // custom commands needs a channel to run in
+	}
+
+	cmd, err := ccmodels.CustomCommands(qm.Where("guild_id = ? AND local_id = ?", ctxData.GS.ID, settingsCast.CommandID)).OneG(context.Background())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil // deleted
+		}
+
+		return err
+	}
+
+	tmplCtx, err := ctxData.templateContext(cs)
+	if err != nil {
+		return err
+	}
+
+	return customcommands.ExecuteCustomCommand(cmd, tmplCtx)
+}
@@ -0,0 +1,528 @@
+package automod
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/automod/models"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/web"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"reflect"
+)
+
+// RulesetExportVersion is the current version of the export format, bump it on breaking changes
+const RulesetExportVersion = 1
+
+// RulesetExport is a full ruleset along with everything it references, in a format that can be imported on other servers
+type RulesetExport struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	DryRun  bool   `json:"dry_run"`
+
+	Conditions []*ExportedRulePart `json:"conditions"`
+	Rules      []*ExportedRule     `json:"rules"`
+
+	// The lists referenced by the parts, the parts reference these by the ID they had on the exporting server
+	Lists []*ExportedList `json:"lists"`
+
+	// The names of the roles and channels referenced, used to help remapping them on import
+	web.ExportedRefs
+}
+
+type ExportedRule struct {
+	Name  string              `json:"name"`
+	Parts []*ExportedRulePart `json:"parts"`
+
+	Cooldown     int  `json:"cooldown"`
+	UserCooldown int  `json:"user_cooldown"`
+	BatchModlog  bool `json:"batch_modlog"`
+}
+
+type ExportedRulePart struct {
+	Kind     int             `json:"kind"`
+	TypeID   int             `json:"type_id"`
+	Settings json.RawMessage `json:"settings"`
+}
+
+type ExportedList struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Kind    int      `json:"kind"`
+	Content []string `json:"content"`
+}
+
+// ExportRuleset creates a export of the ruleset, the ruleset needs to have its rules, rule data and conditions loaded
+func ExportRuleset(ctx context.Context, g *discordgo.Guild, ruleset *models.AutomodRuleset) (*RulesetExport, error) {
+	export := &RulesetExport{
+		Version: RulesetExportVersion,
+		Name:    ruleset.Name,
+		Enabled: ruleset.Enabled,
+		DryRun:  ruleset.DryRun,
+	}
+
+	var listIDs, roleIDs, channelIDs []int64
+	collectRefs := func(typeID int, settings []byte) error {
+		part, ok := RulePartMap[typeID]
+		if !ok {
+			return &ErrUnknownTypeID{typeID}
+		}
+
+		dst := part.DataType()
+		if dst == nil {
+			return nil
+		}
+
+		err := json.Unmarshal(settings, dst)
+		if err != nil {
+			return err
+		}
+
+		walkPartReferences(part, dst, func(kind SettingType, id int64) int64 {
+			dst := &channelIDs
+			switch kind {
+			case SettingTypeList:
+				dst = &listIDs
+			case SettingTypeRole, SettingTypeMultiRole:
+				dst = &roleIDs
+			}
+
+			if !common.ContainsInt64Slice(*dst, id) {
+				*dst = append(*dst, id)
+			}
+			return id
+		})
+
+		return nil
+	}
+
+	for _, v := range ruleset.R.RulesetAutomodRulesetConditions {
+		err := collectRefs(v.TypeID, v.Settings)
+		if err != nil {
+			return nil, err
+		}
+
+		export.Conditions = append(export.Conditions, &ExportedRulePart{
+			Kind:     v.Kind,
+			TypeID:   v.TypeID,
+			Settings: json.RawMessage(v.Settings),
+		})
+	}
+
+	for _, rule := range ruleset.R.RulesetAutomodRules {
+		exportedRule := &ExportedRule{
+			Name:         rule.Name,
+			Cooldown:     rule.Cooldown,
+			UserCooldown: rule.UserCooldown,
+			BatchModlog:  rule.BatchModlog,
+		}
+
+		for _, v := range rule.R.RuleAutomodRuleData {
+			err := collectRefs(v.TypeID, v.Settings)
+			if err != nil {
+				return nil, err
+			}
+
+			exportedRule.Parts = append(exportedRule.Parts, &ExportedRulePart{
+				Kind:     v.Kind,
+				TypeID:   v.TypeID,
+				Settings: json.RawMessage(v.Settings),
+			})
+		}
+
+		export.Rules = append(export.Rules, exportedRule)
+	}
+
+	if len(listIDs) > 0 {
+		lists, err := models.AutomodLists(qm.Where("guild_id = ?", g.ID)).AllG(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range lists {
+			if !common.ContainsInt64Slice(listIDs, v.ID) {
+				continue
+			}
+
+			export.Lists = append(export.Lists, &ExportedList{
+				ID:      v.ID,
+				Name:    v.Name,
+				Kind:    v.Kind,
+				Content: v.Content,
+			})
+		}
+	}
+
+	export.ExportedRefs = web.NewExportedRefs(g, roleIDs, channelIDs)
+	return export, nil
+}
+
+// ParseRulesetExport parses and does some basic sanity checking of a ruleset export
+func ParseRulesetExport(data []byte) (*RulesetExport, error) {
+	var export RulesetExport
+	err := json.Unmarshal(data, &export)
+	if err != nil {
+		return nil, web.NewPublicError("Failed parsing the ruleset export, is it valid JSON?")
+	}
+
+	if export.Version < 1 || export.Version > RulesetExportVersion {
+		return nil, web.NewPublicError(fmt.Sprintf("Unsupported ruleset export version: %d", export.Version))
+	}
+
+	if export.Name == "" {
+		return nil, web.NewPublicError("Ruleset export has no name")
+	}
+
+	for _, v := range export.Conditions {
+		if err := checkExportedPart(v); err != nil {
+			return nil, err
+		}
+
+		if RulePartType(v.Kind) != RulePartCondition {
+			return nil, web.NewPublicError("Ruleset export contains a ruleset condition that is not a condition")
+		}
+	}
+
+	for _, rule := range export.Rules {
+		if len(rule.Parts) > MaxRuleParts {
+			return nil, web.NewPublicError(fmt.Sprintf("Rule %q has more than %d triggers/conditions/effects", rule.Name, MaxRuleParts))
+		}
+
+		for _, v := range rule.Parts {
+			if err := checkExportedPart(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &export, nil
+}
+
+func checkExportedPart(part *ExportedRulePart) error {
+	p, ok := RulePartMap[part.TypeID]
+	if !ok {
+		return web.NewPublicError(fmt.Sprintf("Ruleset export contains a unknown trigger, condition or effect (type id %d)", part.TypeID))
+	}
+
+	if int(p.Kind()) != part.Kind {
+		return web.NewPublicError(fmt.Sprintf("Ruleset export contains a %s with the wrong kind", p.Name()))
+	}
+
+	return nil
+}
+
+// ImportRuleset creates the ruleset from the export, remapping the roles and channels that don't exist on the guild using remap
+// The settings of every part is validated, any validation errors are added as alerts to tmpl and ok is set to false.
+// Everything is created in a single transaction so either everything gets created or nothing.
+func ImportRuleset(ctx context.Context, g *discordgo.Guild, tmpl web.TemplateData, export *RulesetExport, remap *web.ImportRemap) (rs *models.AutomodRuleset, ok bool, err error) {
+	tx, err := common.PQ.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rs, ok, err = importRulesetTx(ctx, tx, g, tmpl, export, remap)
+	if err != nil || !ok {
+		tx.Rollback()
+		return nil, ok, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return rs, true, nil
+}
+
+func importRulesetTx(ctx context.Context, tx *sql.Tx, g *discordgo.Guild, tmpl web.TemplateData, export *RulesetExport, remap *web.ImportRemap) (*models.AutomodRuleset, bool, error) {
+	// lock the tables so that imports running at the same time can't get past the limits together
+	_, err := tx.Exec("LOCK TABLE automod_rulesets, automod_rules, automod_rule_data, automod_lists IN EXCLUSIVE MODE")
+	if err != nil {
+		return nil, false, errors.WithMessage(err, "lock")
+	}
+
+	ok, err := checkImportLimits(ctx, tx, g, tmpl, export)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	listMap, ok, err := importLists(ctx, tx, g, tmpl, export.Lists)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	remapRef := func(kind SettingType, id int64) int64 {
+		switch kind {
+		case SettingTypeList:
+			return listMap[id]
+		case SettingTypeRole, SettingTypeMultiRole:
+			return remap.Role(id)
+		default:
+			return remap.Channel(id)
+		}
+	}
+
+	rs := &models.AutomodRuleset{
+		GuildID: g.ID,
+		Name:    common.CutStringShort(export.Name, 50),
+		Enabled: export.Enabled,
+		DryRun:  export.DryRun,
+	}
+
+	err = rs.Insert(ctx, tx, boil.Infer())
+	if err != nil {
+		return nil, false, errors.WithMessage(err, "insert ruleset")
+	}
+
+	for _, v := range export.Conditions {
+		settings, ok, err := importPartSettings(g, tmpl, v, remapRef)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+
+		cond := &models.AutomodRulesetCondition{
+			GuildID:   g.ID,
+			RulesetID: rs.ID,
+			Kind:      v.Kind,
+			TypeID:    v.TypeID,
+			Settings:  settings,
+		}
+
+		err = cond.Insert(ctx, tx, boil.Infer())
+		if err != nil {
+			return nil, false, errors.WithMessage(err, "insert condition")
+		}
+	}
+
+	for _, v := range export.Rules {
+		rule := &models.AutomodRule{
+			GuildID:      g.ID,
+			RulesetID:    rs.ID,
+			Name:         common.CutStringShort(v.Name, 50),
+			Cooldown:     clampInt(v.Cooldown, 0, 86400),
+			UserCooldown: clampInt(v.UserCooldown, 0, 86400),
+			BatchModlog:  v.BatchModlog,
+		}
+
+		err = rule.Insert(ctx, tx, boil.Infer())
+		if err != nil {
+			return nil, false, errors.WithMessage(err, "insert rule")
+		}
+
+		parts := make([]*models.AutomodRuleDatum, 0, len(v.Parts))
+		for _, p := range v.Parts {
+			settings, ok, err := importPartSettings(g, tmpl, p, remapRef)
+			if err != nil || !ok {
+				return nil, ok, err
+			}
+
+			parts = append(parts, &models.AutomodRuleDatum{
+				GuildID:  g.ID,
+				RuleID:   rule.ID,
+				Kind:     p.Kind,
+				TypeID:   p.TypeID,
+				Settings: settings,
+			})
+		}
+
+		// rules inserted earlier in this transaction is also counted towards the limits here
+		parts, ok, err = CheckLimits(tx, rule, tmpl, parts)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+
+		for _, p := range parts {
+			err = p.Insert(ctx, tx, boil.Infer())
+			if err != nil {
+				return nil, false, errors.WithMessage(err, "insert rule data")
+			}
+		}
+	}
+
+	return rs, true, nil
+}
+
+// checkImportLimits checks that importing the ruleset wouldn't exceed the max number of rulesets or rules
+func checkImportLimits(ctx context.Context, tx *sql.Tx, g *discordgo.Guild, tmpl web.TemplateData, export *RulesetExport) (bool, error) {
+	numRulesets, err := models.AutomodRulesets(qm.Where("guild_id = ?", g.ID)).Count(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if numRulesets >= MaxRulesets {
+		tmpl.AddAlerts(web.ErrorAlert("Reached max number of rulesets, ", MaxRulesets))
+		return false, nil
+	}
+
+	numRules, err := models.AutomodRules(qm.Where("guild_id = ?", g.ID)).Count(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if numRules+int64(len(export.Rules)) > int64(GuildMaxTotalRules(g.ID)) {
+		tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Importing this ruleset would exceed the max number of rules, %d for normal servers and %d for premium servers", MaxTotalRules, MaxTotalRulesPremium)))
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// importLists creates the lists in the export, reusing already existing lists that are identical, returning a map of old id -> new id
+func importLists(ctx context.Context, tx *sql.Tx, g *discordgo.Guild, tmpl web.TemplateData, lists []*ExportedList) (map[int64]int64, bool, error) {
+	result := make(map[int64]int64)
+	if len(lists) < 1 {
+		return result, true, nil
+	}
+
+	existing, err := models.AutomodLists(qm.Where("guild_id = ?", g.ID)).All(ctx, tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	numLists := len(existing)
+
+OUTER:
+	for _, v := range lists {
+		for _, e := range existing {
+			if e.Name == v.Name && e.Kind == v.Kind && listContentEqual(e.Content, v.Content) {
+				result[v.ID] = e.ID
+				continue OUTER
+			}
+		}
+
+		if numLists >= GuildMaxLists(g.ID) {
+			tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Importing this ruleset would exceed the max number of lists, %d for normal servers and %d for premium servers", MaxLists, MaxListsPremium)))
+			return nil, false, nil
+		}
+
+		content := v.Content
+		if content == nil {
+			content = []string{}
+		}
+
+		list := &models.AutomodList{
+			GuildID: g.ID,
+			Name:    common.CutStringShort(v.Name, 50),
+			Kind:    v.Kind,
+			Content: content,
+		}
+
+		err = list.Insert(ctx, tx, boil.Infer())
+		if err != nil {
+			return nil, false, errors.WithMessage(err, "insert list")
+		}
+
+		result[v.ID] = list.ID
+		numLists++
+	}
+
+	return result, true, nil
+}
+
+func clampInt(i, min, max int) int {
+	if i < min {
+		return min
+	}
+
+	if i > max {
+		return max
+	}
+
+	return i
+}
+
+func listContentEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// importPartSettings decodes the settings into the parts DataType, remaps the references and validates it
+func importPartSettings(g *discordgo.Guild, tmpl web.TemplateData, exported *ExportedRulePart, remap func(kind SettingType, id int64) int64) ([]byte, bool, error) {
+	part := RulePartMap[exported.TypeID]
+
+	dst := part.DataType()
+	if dst == nil {
+		return []byte("{}"), true, nil
+	}
+
+	if len(exported.Settings) > 0 {
+		err := json.Unmarshal(exported.Settings, dst)
+		if err != nil {
+			tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Invalid settings for %s", part.Name())))
+			return nil, false, nil
+		}
+	}
+
+	walkPartReferences(part, dst, remap)
+
+	// check the int settings against their limits
+	v := reflect.Indirect(reflect.ValueOf(dst))
+	for _, def := range part.UserSettings() {
+		if def.Kind != SettingTypeInt || def.Max <= def.Min {
+			continue
+		}
+
+		field := v.FieldByName(def.Key)
+		if !field.IsValid() || field.Kind() != reflect.Int {
+			continue
+		}
+
+		if i := int(field.Int()); i < def.Min || i > def.Max {
+			tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("%s: %s has to be between %d and %d", part.Name(), def.Name, def.Min, def.Max)))
+			return nil, false, nil
+		}
+	}
+
+	if !web.ValidateForm(g, tmpl, dst) {
+		return nil, false, nil
+	}
+
+	serialized, err := json.Marshal(dst)
+	return serialized, true, err
+}
+
+// walkPartReferences calls f for every role, channel and list id referenced in the settings, replacing it with the returned value.
+// If f returns 0 the reference is removed.
+func walkPartReferences(part RulePart, settings interface{}, f func(kind SettingType, id int64) int64) {
+	v := reflect.Indirect(reflect.ValueOf(settings))
+
+	for _, def := range part.UserSettings() {
+		switch def.Kind {
+		case SettingTypeRole, SettingTypeMultiRole, SettingTypeChannel, SettingTypeMultiChannel, SettingTypeMultiChannelCategories, SettingTypeList:
+		default:
+			continue
+		}
+
+		field := v.FieldByName(def.Key)
+		if !field.IsValid() {
+			continue
+		}
+
+		switch cast := field.Interface().(type) {
+		case int64:
+			if cast != 0 {
+				field.SetInt(f(def.Kind, cast))
+			}
+		case []int64:
+			newIDs := make([]int64, 0, len(cast))
+			for _, id := range cast {
+				if newID := f(def.Kind, id); newID != 0 {
+					newIDs = append(newIDs, newID)
+				}
+			}
+			field.Set(reflect.ValueOf(newIDs))
+		}
+	}
+}
@@ -28,6 +28,9 @@ type AutomodRule struct {
 	RulesetID      int64  `boil:"ruleset_id" json:"ruleset_id" toml:"ruleset_id" yaml:"ruleset_id"`
 	Name           string `boil:"name" json:"name" toml:"name" yaml:"name"`
 	TriggerCounter int64  `boil:"trigger_counter" json:"trigger_counter" toml:"trigger_counter" yaml:"trigger_counter"`
+	Cooldown       int    `boil:"cooldown" json:"cooldown" toml:"cooldown" yaml:"cooldown"`
+	UserCooldown   int    `boil:"user_cooldown" json:"user_cooldown" toml:"user_cooldown" yaml:"user_cooldown"`
+	BatchModlog    bool   `boil:"batch_modlog" json:"batch_modlog" toml:"batch_modlog" yaml:"batch_modlog"`
 
 	R *automodRuleR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L automodRuleL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -39,12 +42,18 @@ var AutomodRuleColumns = struct {
 	RulesetID      string
 	Name           string
 	TriggerCounter string
+	Cooldown       string
+	UserCooldown   string
+	BatchModlog    string
 }{
 	ID:             "id",
 	GuildID:        "guild_id",
 	RulesetID:      "ruleset_id",
 	Name:           "name",
 	TriggerCounter: "trigger_counter",
+	Cooldown:       "cooldown",
+	UserCooldown:   "user_cooldown",
+	BatchModlog:    "batch_modlog",
 }
 
 // Generated where
@@ -55,12 +64,18 @@ var AutomodRuleWhere = struct {
 	RulesetID      whereHelperint64
 	Name           whereHelperstring
 	TriggerCounter whereHelperint64
+	Cooldown       whereHelperint
+	UserCooldown   whereHelperint
+	BatchModlog    whereHelperbool
 }{
 	ID:             whereHelperint64{field: `id`},
 	GuildID:        whereHelperint64{field: `guild_id`},
 	RulesetID:      whereHelperint64{field: `ruleset_id`},
 	Name:           whereHelperstring{field: `name`},
 	TriggerCounter: whereHelperint64{field: `trigger_counter`},
+	Cooldown:       whereHelperint{field: `cooldown`},
+	UserCooldown:   whereHelperint{field: `user_cooldown`},
+	BatchModlog:    whereHelperbool{field: `batch_modlog`},
 }
 
 // AutomodRuleRels is where relationship names are stored.
@@ -93,9 +108,9 @@ func (*automodRuleR) NewStruct() *automodRuleR {
 type automodRuleL struct{}
 
 var (
-	automodRuleColumns               = []string{"id", "guild_id", "ruleset_id", "name", "trigger_counter"}
+	automodRuleColumns               = []string{"id", "guild_id", "ruleset_id", "name", "trigger_counter", "cooldown", "user_cooldown", "batch_modlog"}
 	automodRuleColumnsWithoutDefault = []string{"guild_id", "ruleset_id", "name", "trigger_counter"}
-	automodRuleColumnsWithDefault    = []string{"id"}
+	automodRuleColumnsWithDefault    = []string{"id", "cooldown", "user_cooldown", "batch_modlog"}
 	automodRulePrimaryKeyColumns     = []string{"id"}
 )
 
@@ -27,6 +27,7 @@ type AutomodRuleset struct {
 	GuildID int64  `boil:"guild_id" json:"guild_id" toml:"guild_id" yaml:"guild_id"`
 	Name    string `boil:"name" json:"name" toml:"name" yaml:"name"`
 	Enabled bool   `boil:"enabled" json:"enabled" toml:"enabled" yaml:"enabled"`
+	DryRun  bool   `boil:"dry_run" json:"dry_run" toml:"dry_run" yaml:"dry_run"`
 
 	R *automodRulesetR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L automodRulesetL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -37,11 +38,13 @@ var AutomodRulesetColumns = struct {
 	GuildID string
 	Name    string
 	Enabled string
+	DryRun  string
 }{
 	ID:      "id",
 	GuildID: "guild_id",
 	Name:    "name",
 	Enabled: "enabled",
+	DryRun:  "dry_run",
 }
 
 // Generated where
@@ -60,11 +63,13 @@ var AutomodRulesetWhere = struct {
 	GuildID whereHelperint64
 	Name    whereHelperstring
 	Enabled whereHelperbool
+	DryRun  whereHelperbool
 }{
 	ID:      whereHelperint64{field: `id`},
 	GuildID: whereHelperint64{field: `guild_id`},
 	Name:    whereHelperstring{field: `name`},
 	Enabled: whereHelperbool{field: `enabled`},
+	DryRun:  whereHelperbool{field: `dry_run`},
 }
 
 // AutomodRulesetRels is where relationship names are stored.
@@ -91,9 +96,9 @@ func (*automodRulesetR) NewStruct() *automodRulesetR {
 type automodRulesetL struct{}
 
 var (
-	automodRulesetColumns               = []string{"id", "guild_id", "name", "enabled"}
+	automodRulesetColumns               = []string{"id", "guild_id", "name", "enabled", "dry_run"}
 	automodRulesetColumnsWithoutDefault = []string{"guild_id", "name", "enabled"}
-	automodRulesetColumnsWithDefault    = []string{"id"}
+	automodRulesetColumnsWithDefault    = []string{"id", "dry_run"}
 	automodRulesetPrimaryKeyColumns     = []string{"id"}
 )
 
@@ -25,19 +25,22 @@ import (
 
 // AutomodTriggeredRule is an object representing the database table.
 type AutomodTriggeredRule struct {
-	ID            int64      `boil:"id" json:"id" toml:"id" yaml:"id"`
-	CreatedAt     time.Time  `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
-	ChannelID     int64      `boil:"channel_id" json:"channel_id" toml:"channel_id" yaml:"channel_id"`
-	ChannelName   string     `boil:"channel_name" json:"channel_name" toml:"channel_name" yaml:"channel_name"`
-	GuildID       int64      `boil:"guild_id" json:"guild_id" toml:"guild_id" yaml:"guild_id"`
-	TriggerID     null.Int64 `boil:"trigger_id" json:"trigger_id,omitempty" toml:"trigger_id" yaml:"trigger_id,omitempty"`
-	TriggerTypeid int        `boil:"trigger_typeid" json:"trigger_typeid" toml:"trigger_typeid" yaml:"trigger_typeid"`
-	RuleID        null.Int64 `boil:"rule_id" json:"rule_id,omitempty" toml:"rule_id" yaml:"rule_id,omitempty"`
-	RuleName      string     `boil:"rule_name" json:"rule_name" toml:"rule_name" yaml:"rule_name"`
-	RulesetName   string     `boil:"ruleset_name" json:"ruleset_name" toml:"ruleset_name" yaml:"ruleset_name"`
-	UserID        int64      `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
-	UserName      string     `boil:"user_name" json:"user_name" toml:"user_name" yaml:"user_name"`
-	Extradata     types.JSON `boil:"extradata" json:"extradata" toml:"extradata" yaml:"extradata"`
+	ID            int64             `boil:"id" json:"id" toml:"id" yaml:"id"`
+	CreatedAt     time.Time         `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
+	ChannelID     int64             `boil:"channel_id" json:"channel_id" toml:"channel_id" yaml:"channel_id"`
+	ChannelName   string            `boil:"channel_name" json:"channel_name" toml:"channel_name" yaml:"channel_name"`
+	GuildID       int64             `boil:"guild_id" json:"guild_id" toml:"guild_id" yaml:"guild_id"`
+	TriggerID     null.Int64        `boil:"trigger_id" json:"trigger_id,omitempty" toml:"trigger_id" yaml:"trigger_id,omitempty"`
+	TriggerTypeid int               `boil:"trigger_typeid" json:"trigger_typeid" toml:"trigger_typeid" yaml:"trigger_typeid"`
+	RuleID        null.Int64        `boil:"rule_id" json:"rule_id,omitempty" toml:"rule_id" yaml:"rule_id,omitempty"`
+	RuleName      string            `boil:"rule_name" json:"rule_name" toml:"rule_name" yaml:"rule_name"`
+	RulesetName   string            `boil:"ruleset_name" json:"ruleset_name" toml:"ruleset_name" yaml:"ruleset_name"`
+	UserID        int64             `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
+	UserName      string            `boil:"user_name" json:"user_name" toml:"user_name" yaml:"user_name"`
+	Extradata     types.JSON        `boil:"extradata" json:"extradata" toml:"extradata" yaml:"extradata"`
+	DryRun        bool              `boil:"dry_run" json:"dry_run" toml:"dry_run" yaml:"dry_run"`
+	Effects       types.StringArray `boil:"effects" json:"effects" toml:"effects" yaml:"effects"`
+	OnCooldown    bool              `boil:"on_cooldown" json:"on_cooldown" toml:"on_cooldown" yaml:"on_cooldown"`
 
 	R *automodTriggeredRuleR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L automodTriggeredRuleL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -57,6 +60,9 @@ var AutomodTriggeredRuleColumns = struct {
 	UserID        string
 	UserName      string
 	Extradata     string
+	DryRun        string
+	Effects       string
+	OnCooldown    string
 }{
 	ID:            "id",
 	CreatedAt:     "created_at",
@@ -71,6 +77,9 @@ var AutomodTriggeredRuleColumns = struct {
 	UserID:        "user_id",
 	UserName:      "user_name",
 	Extradata:     "extradata",
+	DryRun:        "dry_run",
+	Effects:       "effects",
+	OnCooldown:    "on_cooldown",
 }
 
 // Generated where
@@ -133,6 +142,9 @@ var AutomodTriggeredRuleWhere = struct {
 	UserID        whereHelperint64
 	UserName      whereHelperstring
 	Extradata     whereHelpertypes_JSON
+	DryRun        whereHelperbool
+	Effects       whereHelpertypes_StringArray
+	OnCooldown    whereHelperbool
 }{
 	ID:            whereHelperint64{field: `id`},
 	CreatedAt:     whereHelpertime_Time{field: `created_at`},
@@ -147,6 +159,9 @@ var AutomodTriggeredRuleWhere = struct {
 	UserID:        whereHelperint64{field: `user_id`},
 	UserName:      whereHelperstring{field: `user_name`},
 	Extradata:     whereHelpertypes_JSON{field: `extradata`},
+	DryRun:        whereHelperbool{field: `dry_run`},
+	Effects:       whereHelpertypes_StringArray{field: `effects`},
+	OnCooldown:    whereHelperbool{field: `on_cooldown`},
 }
 
 // AutomodTriggeredRuleRels is where relationship names are stored.
@@ -173,9 +188,9 @@ func (*automodTriggeredRuleR) NewStruct() *automodTriggeredRuleR {
 type automodTriggeredRuleL struct{}
 
 var (
-	automodTriggeredRuleColumns               = []string{"id", "created_at", "channel_id", "channel_name", "guild_id", "trigger_id", "trigger_typeid", "rule_id", "rule_name", "ruleset_name", "user_id", "user_name", "extradata"}
+	automodTriggeredRuleColumns               = []string{"id", "created_at", "channel_id", "channel_name", "guild_id", "trigger_id", "trigger_typeid", "rule_id", "rule_name", "ruleset_name", "user_id", "user_name", "extradata", "dry_run", "effects", "on_cooldown"}
 	automodTriggeredRuleColumnsWithoutDefault = []string{"created_at", "channel_id", "channel_name", "guild_id", "trigger_id", "trigger_typeid", "rule_id", "rule_name", "ruleset_name", "user_id", "user_name", "extradata"}
-	automodTriggeredRuleColumnsWithDefault    = []string{"id"}
+	automodTriggeredRuleColumnsWithDefault    = []string{"id", "dry_run", "effects", "on_cooldown"}
 	automodTriggeredRulePrimaryKeyColumns     = []string{"id"}
 )
 
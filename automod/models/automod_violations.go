@@ -30,6 +30,7 @@ type AutomodViolation struct {
 	RuleID    null.Int64 `boil:"rule_id" json:"rule_id,omitempty" toml:"rule_id" yaml:"rule_id,omitempty"`
 	CreatedAt time.Time  `boil:"created_at" json:"created_at" toml:"created_at" yaml:"created_at"`
 	Name      string     `boil:"name" json:"name" toml:"name" yaml:"name"`
+	DecayedAt null.Time  `boil:"decayed_at" json:"decayed_at,omitempty" toml:"decayed_at" yaml:"decayed_at,omitempty"`
 
 	R *automodViolationR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L automodViolationL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -42,6 +43,7 @@ var AutomodViolationColumns = struct {
 	RuleID    string
 	CreatedAt string
 	Name      string
+	DecayedAt string
 }{
 	ID:        "id",
 	GuildID:   "guild_id",
@@ -49,10 +51,34 @@ var AutomodViolationColumns = struct {
 	RuleID:    "rule_id",
 	CreatedAt: "created_at",
 	Name:      "name",
+	DecayedAt: "decayed_at",
 }
 
 // Generated where
 
+type whereHelpernull_Time struct{ field string }
+
+func (w whereHelpernull_Time) EQ(x null.Time) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, false, x)
+}
+func (w whereHelpernull_Time) NEQ(x null.Time) qm.QueryMod {
+	return qmhelper.WhereNullEQ(w.field, true, x)
+}
+func (w whereHelpernull_Time) IsNull() qm.QueryMod    { return qmhelper.WhereIsNull(w.field) }
+func (w whereHelpernull_Time) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
+func (w whereHelpernull_Time) LT(x null.Time) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LT, x)
+}
+func (w whereHelpernull_Time) LTE(x null.Time) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.LTE, x)
+}
+func (w whereHelpernull_Time) GT(x null.Time) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GT, x)
+}
+func (w whereHelpernull_Time) GTE(x null.Time) qm.QueryMod {
+	return qmhelper.Where(w.field, qmhelper.GTE, x)
+}
+
 var AutomodViolationWhere = struct {
 	ID        whereHelperint64
 	GuildID   whereHelperint64
@@ -60,6 +86,7 @@ var AutomodViolationWhere = struct {
 	RuleID    whereHelpernull_Int64
 	CreatedAt whereHelpertime_Time
 	Name      whereHelperstring
+	DecayedAt whereHelpernull_Time
 }{
 	ID:        whereHelperint64{field: `id`},
 	GuildID:   whereHelperint64{field: `guild_id`},
@@ -67,6 +94,7 @@ var AutomodViolationWhere = struct {
 	RuleID:    whereHelpernull_Int64{field: `rule_id`},
 	CreatedAt: whereHelpertime_Time{field: `created_at`},
 	Name:      whereHelperstring{field: `name`},
+	DecayedAt: whereHelpernull_Time{field: `decayed_at`},
 }
 
 // AutomodViolationRels is where relationship names are stored.
@@ -90,9 +118,9 @@ func (*automodViolationR) NewStruct() *automodViolationR {
 type automodViolationL struct{}
 
 var (
-	automodViolationColumns               = []string{"id", "guild_id", "user_id", "rule_id", "created_at", "name"}
+	automodViolationColumns               = []string{"id", "guild_id", "user_id", "rule_id", "created_at", "name", "decayed_at"}
 	automodViolationColumnsWithoutDefault = []string{"guild_id", "user_id", "rule_id", "created_at", "name"}
-	automodViolationColumnsWithDefault    = []string{"id"}
+	automodViolationColumnsWithDefault    = []string{"id", "decayed_at"}
 	automodViolationPrimaryKeyColumns     = []string{"id"}
 )
 
@@ -587,7 +587,7 @@ func testAutomodViolationsSelect(t *testing.T) {
 }
 
 var (
-	automodViolationDBTypes = map[string]string{`ID`: `bigint`, `GuildID`: `bigint`, `UserID`: `bigint`, `RuleID`: `bigint`, `CreatedAt`: `timestamp with time zone`, `Name`: `text`}
+	automodViolationDBTypes = map[string]string{`ID`: `bigint`, `GuildID`: `bigint`, `UserID`: `bigint`, `RuleID`: `bigint`, `CreatedAt`: `timestamp with time zone`, `Name`: `text`, `DecayedAt`: `timestamp with time zone`}
 	_                       = bytes.MinRead
 )
 
@@ -18,6 +18,7 @@ func TestParent(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditions)
 	t.Run("AutomodRulesets", testAutomodRulesets)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRules)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPolicies)
 	t.Run("AutomodViolations", testAutomodViolations)
 }
 
@@ -28,6 +29,7 @@ func TestDelete(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsDelete)
 	t.Run("AutomodRulesets", testAutomodRulesetsDelete)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesDelete)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesDelete)
 	t.Run("AutomodViolations", testAutomodViolationsDelete)
 }
 
@@ -38,6 +40,7 @@ func TestQueryDeleteAll(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsQueryDeleteAll)
 	t.Run("AutomodRulesets", testAutomodRulesetsQueryDeleteAll)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesQueryDeleteAll)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesQueryDeleteAll)
 	t.Run("AutomodViolations", testAutomodViolationsQueryDeleteAll)
 }
 
@@ -48,6 +51,7 @@ func TestSliceDeleteAll(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsSliceDeleteAll)
 	t.Run("AutomodRulesets", testAutomodRulesetsSliceDeleteAll)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesSliceDeleteAll)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesSliceDeleteAll)
 	t.Run("AutomodViolations", testAutomodViolationsSliceDeleteAll)
 }
 
@@ -58,6 +62,7 @@ func TestExists(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsExists)
 	t.Run("AutomodRulesets", testAutomodRulesetsExists)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesExists)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesExists)
 	t.Run("AutomodViolations", testAutomodViolationsExists)
 }
 
@@ -68,6 +73,7 @@ func TestFind(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsFind)
 	t.Run("AutomodRulesets", testAutomodRulesetsFind)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesFind)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesFind)
 	t.Run("AutomodViolations", testAutomodViolationsFind)
 }
 
@@ -78,6 +84,7 @@ func TestBind(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsBind)
 	t.Run("AutomodRulesets", testAutomodRulesetsBind)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesBind)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesBind)
 	t.Run("AutomodViolations", testAutomodViolationsBind)
 }
 
@@ -88,6 +95,7 @@ func TestOne(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsOne)
 	t.Run("AutomodRulesets", testAutomodRulesetsOne)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesOne)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesOne)
 	t.Run("AutomodViolations", testAutomodViolationsOne)
 }
 
@@ -98,6 +106,7 @@ func TestAll(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsAll)
 	t.Run("AutomodRulesets", testAutomodRulesetsAll)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesAll)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesAll)
 	t.Run("AutomodViolations", testAutomodViolationsAll)
 }
 
@@ -108,6 +117,7 @@ func TestCount(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsCount)
 	t.Run("AutomodRulesets", testAutomodRulesetsCount)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesCount)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesCount)
 	t.Run("AutomodViolations", testAutomodViolationsCount)
 }
 
@@ -123,7 +133,9 @@ func TestInsert(t *testing.T) {
 	t.Run("AutomodRulesets", testAutomodRulesetsInsert)
 	t.Run("AutomodRulesets", testAutomodRulesetsInsertWhitelist)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesInsert)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesInsert)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesInsertWhitelist)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesInsertWhitelist)
 	t.Run("AutomodViolations", testAutomodViolationsInsert)
 	t.Run("AutomodViolations", testAutomodViolationsInsertWhitelist)
 }
@@ -215,6 +227,7 @@ func TestReload(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsReload)
 	t.Run("AutomodRulesets", testAutomodRulesetsReload)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesReload)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesReload)
 	t.Run("AutomodViolations", testAutomodViolationsReload)
 }
 
@@ -225,6 +238,7 @@ func TestReloadAll(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsReloadAll)
 	t.Run("AutomodRulesets", testAutomodRulesetsReloadAll)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesReloadAll)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesReloadAll)
 	t.Run("AutomodViolations", testAutomodViolationsReloadAll)
 }
 
@@ -235,6 +249,7 @@ func TestSelect(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsSelect)
 	t.Run("AutomodRulesets", testAutomodRulesetsSelect)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesSelect)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesSelect)
 	t.Run("AutomodViolations", testAutomodViolationsSelect)
 }
 
@@ -245,6 +260,7 @@ func TestUpdate(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsUpdate)
 	t.Run("AutomodRulesets", testAutomodRulesetsUpdate)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesUpdate)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesUpdate)
 	t.Run("AutomodViolations", testAutomodViolationsUpdate)
 }
 
@@ -255,5 +271,6 @@ func TestSliceUpdateAll(t *testing.T) {
 	t.Run("AutomodRulesetConditions", testAutomodRulesetConditionsSliceUpdateAll)
 	t.Run("AutomodRulesets", testAutomodRulesetsSliceUpdateAll)
 	t.Run("AutomodTriggeredRules", testAutomodTriggeredRulesSliceUpdateAll)
+	t.Run("AutomodViolationDecayPolicies", testAutomodViolationDecayPoliciesSliceUpdateAll)
 	t.Run("AutomodViolations", testAutomodViolationsSliceUpdateAll)
 }
@@ -4,19 +4,21 @@
 package models
 
 var TableNames = struct {
-	AutomodLists             string
-	AutomodRuleData          string
-	AutomodRules             string
-	AutomodRulesetConditions string
-	AutomodRulesets          string
-	AutomodTriggeredRules    string
-	AutomodViolations        string
+	AutomodLists                  string
+	AutomodRuleData               string
+	AutomodRules                  string
+	AutomodRulesetConditions      string
+	AutomodRulesets               string
+	AutomodTriggeredRules         string
+	AutomodViolationDecayPolicies string
+	AutomodViolations             string
 }{
-	AutomodLists:             "automod_lists",
-	AutomodRuleData:          "automod_rule_data",
-	AutomodRules:             "automod_rules",
-	AutomodRulesetConditions: "automod_ruleset_conditions",
-	AutomodRulesets:          "automod_rulesets",
-	AutomodTriggeredRules:    "automod_triggered_rules",
-	AutomodViolations:        "automod_violations",
+	AutomodLists:                  "automod_lists",
+	AutomodRuleData:               "automod_rule_data",
+	AutomodRules:                  "automod_rules",
+	AutomodRulesetConditions:      "automod_ruleset_conditions",
+	AutomodRulesets:               "automod_rulesets",
+	AutomodTriggeredRules:         "automod_triggered_rules",
+	AutomodViolationDecayPolicies: "automod_violation_decay_policies",
+	AutomodViolations:             "automod_violations",
 }
@@ -0,0 +1,65 @@
+package automod
+
+import (
+	"encoding/json"
+	"github.com/jonas747/yagpdb/bot"
+	"github.com/jonas747/yagpdb/bot/botrest"
+	"github.com/pkg/errors"
+	"goji.io"
+	"goji.io/pat"
+	"net/http"
+	"strconv"
+)
+
+var _ botrest.BotRestPlugin = (*Plugin)(nil)
+
+func (p *Plugin) InitBotRestServer(mux *goji.Mux) {
+	mux.Handle(pat.Post("/:guild/automod/test"), http.HandlerFunc(p.botRestHandleTestMessage))
+}
+
+type TestMessageRequest struct {
+	ChannelID int64
+	UserID    int64
+	Content   string
+}
+
+func (p *Plugin) botRestHandleTestMessage(w http.ResponseWriter, r *http.Request) {
+	guildID := pat.Param(r, "guild")
+	parsedGID, _ := strconv.ParseInt(guildID, 10, 64)
+
+	gs := bot.State.Guild(true, parsedGID)
+	if gs == nil {
+		botrest.ServerError(w, r, errors.New("unknown server"))
+		return
+	}
+
+	var req TestMessageRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if botrest.ServerError(w, r, err) {
+		return
+	}
+
+	cs := gs.Channel(true, req.ChannelID)
+	if cs == nil {
+		botrest.ServerError(w, r, errors.New("unknown channel"))
+		return
+	}
+
+	ms, err := bot.GetMember(parsedGID, req.UserID)
+	if err != nil {
+		botrest.ServerError(w, r, errors.WithMessage(err, "unknown member"))
+		return
+	}
+
+	results, err := p.TestMessage(ms, cs, req.Content)
+	if botrest.ServerError(w, r, err) {
+		return
+	}
+
+	botrest.ServeJson(w, r, results)
+}
+
+func botRestPostTestMessage(guildID int64, req *TestMessageRequest) (results []*RulesetTestResult, err error) {
+	err = botrest.Post(bot.GuildShardID(guildID), strconv.FormatInt(guildID, 10)+"/automod/test", req, &results)
+	return
+}
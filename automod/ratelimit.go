@@ -0,0 +1,195 @@
+package automod
+
+import (
+	"fmt"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/moderation"
+	"github.com/mediocregopher/radix"
+	"github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func RedisKeyRuleCooldown(ruleID int64) string {
+	return "automod_rule_cooldown:" + strconv.FormatInt(ruleID, 10)
+}
+
+func RedisKeyRuleUserCooldown(ruleID, userID int64) string {
+	return "automod_rule_user_cooldown:" + strconv.FormatInt(ruleID, 10) + ":" + strconv.FormatInt(userID, 10)
+}
+
+// checkRuleCooldowns returns true if the effects of the rule can be applied to the user,
+// putting the rule and user on cooldown if so
+func checkRuleCooldowns(rule *ParsedRule, userID int64) (bool, error) {
+	if rule.Model.UserCooldown > 0 {
+		ok, err := setCooldown(RedisKeyRuleUserCooldown(rule.Model.ID, userID), rule.Model.UserCooldown)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	if rule.Model.Cooldown > 0 {
+		ok, err := setCooldown(RedisKeyRuleCooldown(rule.Model.ID), rule.Model.Cooldown)
+		if err != nil {
+			return false, err
+		}
+
+		if !ok {
+			if rule.Model.UserCooldown > 0 {
+				// effects were not applied to this user, so don't put them on cooldown
+				common.RedisPool.Do(radix.Cmd(nil, "DEL", RedisKeyRuleUserCooldown(rule.Model.ID, userID)))
+			}
+
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// setCooldown returns true if the key was not already on cooldown
+func setCooldown(key string, seconds int) (bool, error) {
+	var resp string
+	err := common.RedisPool.Do(radix.Cmd(&resp, "SET", key, "1", "EX", strconv.Itoa(seconds), "NX"))
+	if err != nil {
+		return false, err
+	}
+
+	return resp == "OK", nil
+}
+
+// moderationConfig returns the moderation config moderation effects should use, linking the cases created to the rule.
+// If the current rule batches the modlog entries then the action channel is cleared so that they're not posted individually,
+// batched is true in that case
+func (t *TriggeredRuleData) moderationConfig() (config *moderation.Config, batched bool, err error) {
+	cached, err := moderation.GetConfig(t.GS.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// the returned config may be shared with the config cache, so work on a copy
+	c := *cached
+	c.AutomodTrigger = t.caseTriggerName()
+
+	if t.CurrentRule == nil || !t.CurrentRule.Model.BatchModlog {
+		return &c, false, nil
+	}
+
+	c.ActionChannel = ""
+	return &c, true, nil
+}
+
+// caseTriggerName returns the name of the rule and triggers that caused the effects to be applied, as shown in moderation cases
+func (t *TriggeredRuleData) caseTriggerName() string {
+	if t.CurrentRule == nil {
+		return "Automod"
+	}
+
+	name := t.Ruleset.RSModel.Name + ": " + t.CurrentRule.Model.Name
+
+	var triggers []string
+	for _, v := range t.ActivatedTriggers {
+		if v.ParentRule == t.CurrentRule {
+			triggers = append(triggers, v.Part.Name())
+		}
+	}
+
+	if len(triggers) > 0 {
+		name += " (" + strings.Join(triggers, ", ") + ")"
+	}
+
+	return name
+}
+
+// modlogBatchDelay is how long entries are collected before they're posted to the modlog
+const modlogBatchDelay = time.Second * 10
+
+var (
+	modlogBatches   = make(map[int64][]string)
+	modlogBatchesMU sync.Mutex
+)
+
+// addModlogBatchEntry queues a entry to be posted to the modlog along with others in a single message
+func (t *TriggeredRuleData) addModlogBatchEntry(action moderation.ModlogAction, target *discordgo.User) {
+	ruleName := "unknown rule"
+	if t.CurrentRule != nil {
+		ruleName = t.CurrentRule.Model.Name
+	}
+
+	entry := fmt.Sprintf("%s%s **%s**#%s *(ID %d)* - %s", action.Emoji, action.Prefix, target.Username, target.Discriminator, target.ID, ruleName)
+
+	modlogBatchesMU.Lock()
+	defer modlogBatchesMU.Unlock()
+
+	guildID := t.GS.ID
+	if len(modlogBatches[guildID]) < 1 {
+		time.AfterFunc(modlogBatchDelay, func() {
+			flushModlogBatch(guildID)
+		})
+	}
+
+	modlogBatches[guildID] = append(modlogBatches[guildID], entry)
+}
+
+func flushModlogBatch(guildID int64) {
+	modlogBatchesMU.Lock()
+	entries := modlogBatches[guildID]
+	delete(modlogBatches, guildID)
+	modlogBatchesMU.Unlock()
+
+	if len(entries) < 1 {
+		return
+	}
+
+	config, err := moderation.GetConfig(guildID)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", guildID).Error("automod: failed retrieving moderation config for batched modlog")
+		return
+	}
+
+	channelID := config.IntActionChannel()
+	if channelID == 0 {
+		return
+	}
+
+	// split it up into multiple embeds if needed
+	var descriptions []string
+	var current strings.Builder
+	for _, v := range entries {
+		if current.Len()+len(v)+1 > 2000 {
+			descriptions = append(descriptions, current.String())
+			current.Reset()
+		}
+
+		current.WriteString(v)
+		current.WriteString("\n")
+	}
+	descriptions = append(descriptions, current.String())
+
+	for i, v := range descriptions {
+		embed := &discordgo.MessageEmbed{
+			Author: &discordgo.MessageEmbedAuthor{
+				Name:    fmt.Sprintf("%s#%s (ID %d)", common.BotUser.Username, common.BotUser.Discriminator, common.BotUser.ID),
+				IconURL: discordgo.EndpointUserAvatar(common.BotUser.ID, common.BotUser.Avatar),
+			},
+			Title:       fmt.Sprintf("Automoderator: %d actions", len(entries)),
+			Description: v,
+			Color:       0xf2a013,
+		}
+
+		if len(descriptions) > 1 {
+			embed.Footer = &discordgo.MessageEmbedFooter{
+				Text: fmt.Sprintf("Part %d/%d", i+1, len(descriptions)),
+			}
+		}
+
+		_, err := common.BotSession.ChannelMessageSendEmbed(channelID, embed)
+		if err != nil {
+			logrus.WithError(err).WithField("guild", guildID).Error("automod: failed sending batched modlog")
+			return
+		}
+	}
+}
@@ -44,6 +44,18 @@ var RulePartMap = map[int]RulePart{
 	30: &MemberJoinTrigger{},
 	31: &MessageAttachmentTrigger{},
 	32: &MessageAttachmentTrigger{RequiresAttachment: true},
+	33: &ReactionSpamTrigger{},
+	34: &ReactionEmojiTrigger{Blacklist: true},
+	35: &ReactionEmojiTrigger{Blacklist: false},
+	36: &ReactionAccountAgeTrigger{},
+	37: &CrossChannelSpamTrigger{},
+	38: &RaidTrigger{},
+	39: &AttachmentExtensionTrigger{Blacklist: true},
+	40: &AttachmentExtensionTrigger{Blacklist: false},
+	41: &AttachmentSizeTrigger{},
+	42: &SpoilerAttachmentTrigger{},
+	43: &EmbedDomainTrigger{Blacklist: true},
+	44: &EmbedDomainTrigger{Blacklist: false},
 
 	// Conditions 2xx
 	200: &MemberRolesCondition{Blacklist: true},
@@ -72,6 +84,11 @@ var RulePartMap = map[int]RulePart{
 	307: &ResetViolationsEffect{},
 	308: &DeleteMessagesEffect{},
 	309: &GiveRoleEffect{},
+	310: &RemoveReactionEffect{},
+	311: &VerificationLockdownEffect{},
+	312: &SendMessageEffect{},
+	313: &DMUserEffect{},
+	314: &RunCustomCommandEffect{},
 }
 
 var InverseRulePartMap = make(map[RulePart]int)
@@ -165,11 +182,15 @@ type TriggeredRuleData struct {
 	CS                     *dstate.ChannelState
 	Message                *discordgo.Message
 	StrippedMessageContent string // message content stripped of markdown
+	Reaction               *discordgo.MessageReaction
 
 	RecursionCounter int
 
 	// Gets added to when we recurse using +violation
 	PreviousReasons []string
+
+	// If set, the checks are reported to it and no effects are applied or logged, used by the rule tester
+	Reporter CheckReporter
 }
 
 func (t *TriggeredRuleData) Clone() *TriggeredRuleData {
@@ -239,3 +260,18 @@ type JoinListener interface {
 
 	CheckJoin(ms *dstate.MemberState, data interface{}) (isAffected bool, err error)
 }
+
+// ReactionListener is a trigger that gets triggered when a reaction is added to a message
+type ReactionListener interface {
+	RulePart
+
+	CheckReaction(ms *dstate.MemberState, cs *dstate.ChannelState, reaction *discordgo.MessageReaction, data interface{}) (isAffected bool, err error)
+}
+
+// BurstTrigger is a trigger that can affect more members than the one that activated it, such as the members that joined during a raid.
+// The effects of the rule gets applied to the returned members in addition to the one that activated it.
+type BurstTrigger interface {
+	RulePart
+
+	BurstMembers(ctxData *TriggeredRuleData, data interface{}) (userIDs []int64, err error)
+}
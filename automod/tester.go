@@ -0,0 +1,266 @@
+package automod
+
+import (
+	"fmt"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/dstate"
+	"strings"
+)
+
+// RulesetTestResult is the result of running a test message through a single ruleset
+type RulesetTestResult struct {
+	RulesetID   int64
+	RulesetName string
+	Enabled     bool
+	DryRun      bool
+
+	ConditionsPassed bool
+	Conditions       []*PartTestResult
+	Rules            []*RuleTestResult
+}
+
+// RuleTestResult is the result of running a test message through a single rule
+type RuleTestResult struct {
+	RuleID   int64
+	RuleName string
+
+	ConditionsPassed bool
+	Conditions       []*PartTestResult
+	Triggers         []*PartTestResult
+
+	// True if both the ruleset and rule conditions passed and atleast one trigger matched
+	Triggered bool
+
+	// Summaries of the effects that would have been applied, only set if Triggered is true
+	Effects []string
+}
+
+// PartTestResult is the result of checking a single trigger or condition
+type PartTestResult struct {
+	TypeID int
+	Name   string
+
+	Passed bool
+
+	// Set for parts that were never reached, e.g conditions after one that failed, or triggers after one that matched
+	NotChecked bool `json:",omitempty"`
+	// Set for triggers that don't listen for messages (e.g nickname triggers), these are never checked
+	NotApplicable bool   `json:",omitempty"`
+	Error         string `json:",omitempty"`
+}
+
+// TestMessage runs the provided message content through CheckTriggers for all the rulesets on the guild,
+// and reports the result of every condition and trigger checked without applying any effects or logging anything
+func (p *Plugin) TestMessage(ms *dstate.MemberState, cs *dstate.ChannelState, content string) ([]*RulesetTestResult, error) {
+	rulesets, err := p.FetchGuildRulesets(ms.Guild)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &discordgo.Message{
+		ChannelID: cs.ID,
+		GuildID:   ms.Guild.ID,
+		Author:    ms.DGoUser(),
+		Content:   content,
+	}
+
+	reporter := newTestReporter(rulesets)
+	if len(rulesets) > 0 {
+		p.CheckTriggersCtx(rulesets, &TriggeredRuleData{MS: ms, CS: cs, Message: msg, Reporter: reporter}, messageTriggerCheckFunc(ms, cs, msg))
+	}
+
+	return reporter.finish(), nil
+}
+
+// testReporter collects the checks reported by CheckTriggersCtx into test results
+type testReporter struct {
+	results []*RulesetTestResult
+	rules   map[*ParsedRule]*RuleTestResult
+	parts   map[*ParsedPart]*PartTestResult
+}
+
+var _ CheckReporter = (*testReporter)(nil)
+
+// newTestReporter sets up the results for all the rulesets, with every part not checked until it's reported
+func newTestReporter(rulesets []*ParsedRuleset) *testReporter {
+	r := &testReporter{
+		rules: make(map[*ParsedRule]*RuleTestResult),
+		parts: make(map[*ParsedPart]*PartTestResult),
+	}
+
+	for _, rs := range rulesets {
+		rsResult := &RulesetTestResult{
+			RulesetID:   rs.RSModel.ID,
+			RulesetName: rs.RSModel.Name,
+			Enabled:     rs.RSModel.Enabled,
+			DryRun:      rs.RSModel.DryRun,
+			Conditions:  r.addParts(rs.ParsedConditions),
+		}
+
+		for _, rule := range rs.Rules {
+			ruleResult := &RuleTestResult{
+				RuleID:     rule.Model.ID,
+				RuleName:   rule.Model.Name,
+				Conditions: r.addParts(rule.Conditions),
+				Triggers:   r.addParts(rule.Triggers),
+			}
+
+			for i, trig := range rule.Triggers {
+				if _, ok := trig.Part.(MessageTrigger); !ok {
+					ruleResult.Triggers[i].NotApplicable = true
+				}
+			}
+
+			r.rules[rule] = ruleResult
+			rsResult.Rules = append(rsResult.Rules, ruleResult)
+		}
+
+		r.results = append(r.results, rsResult)
+	}
+
+	return r
+}
+
+func (r *testReporter) addParts(parts []*ParsedPart) []*PartTestResult {
+	results := make([]*PartTestResult, 0, len(parts))
+	for _, part := range parts {
+		result := &PartTestResult{
+			Name:       part.Part.Name(),
+			NotChecked: true,
+		}
+
+		if part.RuleModel != nil {
+			result.TypeID = part.RuleModel.TypeID
+		} else if part.RSConditionModel != nil {
+			result.TypeID = part.RSConditionModel.TypeID
+		}
+
+		r.parts[part] = result
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func (r *testReporter) reportPart(part *ParsedPart, passed bool, err error) {
+	result, ok := r.parts[part]
+	if !ok {
+		return
+	}
+
+	result.NotChecked = false
+	result.Passed = passed
+	if err != nil {
+		result.Error = err.Error()
+	}
+}
+
+func (r *testReporter) ConditionChecked(ctxData *TriggeredRuleData, cond *ParsedPart, met bool, err error) {
+	r.reportPart(cond, met, err)
+}
+
+func (r *testReporter) TriggerChecked(ctxData *TriggeredRuleData, trig *ParsedPart, activated bool, err error) {
+	if result, ok := r.parts[trig]; ok && result.NotApplicable {
+		return
+	}
+
+	r.reportPart(trig, activated, err)
+}
+
+func (r *testReporter) RuleTriggered(ctxData *TriggeredRuleData, rule *ParsedRule) {
+	result, ok := r.rules[rule]
+	if !ok {
+		return
+	}
+
+	result.Triggered = true
+	for _, effect := range rule.Effects {
+		result.Effects = append(result.Effects, effect.Summary())
+	}
+}
+
+// finish fills in whether the conditions passed and returns the results
+func (r *testReporter) finish() []*RulesetTestResult {
+	for _, rs := range r.results {
+		rs.ConditionsPassed = rs.Enabled && partsPassed(rs.Conditions)
+		for _, rule := range rs.Rules {
+			rule.ConditionsPassed = rs.ConditionsPassed && partsPassed(rule.Conditions)
+		}
+	}
+
+	return r.results
+}
+
+func partsPassed(parts []*PartTestResult) bool {
+	for _, v := range parts {
+		if v.NotChecked || !v.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FormatTestResults returns a human readable representation of the test results, suitable for sending in a discord message
+func FormatTestResults(results []*RulesetTestResult) string {
+	if len(results) < 1 {
+		return "No automod v2 rulesets set up on this server"
+	}
+
+	var out strings.Builder
+	for _, rs := range results {
+		status := "enabled"
+		if !rs.Enabled {
+			status = "disabled"
+		} else if rs.DryRun {
+			status = "dry run"
+		}
+
+		out.WriteString(fmt.Sprintf("**Ruleset %s** (%s)\n", rs.RulesetName, status))
+		if !rs.Enabled {
+			// disabled rulesets are never checked
+			continue
+		}
+
+		writeTestPartResults(&out, "Ruleset condition", rs.Conditions)
+
+		for _, rule := range rs.Rules {
+			out.WriteString(fmt.Sprintf("> Rule **%s**\n", rule.RuleName))
+			writeTestPartResults(&out, "Condition", rule.Conditions)
+			writeTestPartResults(&out, "Trigger", rule.Triggers)
+
+			if !rule.Triggered {
+				out.WriteString("> Not triggered\n")
+				continue
+			}
+
+			if len(rule.Effects) < 1 {
+				out.WriteString("> Triggered, but has no effects\n")
+				continue
+			}
+
+			out.WriteString("> Triggered, effects: " + strings.Join(rule.Effects, ", ") + "\n")
+		}
+
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+func writeTestPartResults(out *strings.Builder, prefix string, parts []*PartTestResult) {
+	for _, v := range parts {
+		indicator := "❌"
+		if v.NotApplicable || v.NotChecked {
+			indicator = "➖"
+		} else if v.Passed {
+			indicator = "✅"
+		}
+
+		out.WriteString(fmt.Sprintf("> %s %s: `%s`", indicator, prefix, v.Name))
+		if v.Error != "" {
+			out.WriteString(" (error: " + v.Error + ")")
+		}
+		out.WriteString("\n")
+	}
+}
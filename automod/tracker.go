@@ -0,0 +1,89 @@
+package automod
+
+import (
+	"sync"
+	"time"
+)
+
+// trackedEvent is a single event (such as a reaction or a join) tracked by a eventTracker
+type trackedEvent struct {
+	At     time.Time
+	UserID int64
+
+	// Optional extra data, depends on the tracker
+	Data interface{}
+}
+
+// eventTracker keeps a in-memory history of recent events, used by triggers that needs to look at the rate of things
+// that the state does not keep track of (such as reactions)
+type eventTracker struct {
+	mu     sync.Mutex
+	events map[interface{}][]*trackedEvent
+
+	// events older than this are discarded
+	maxAge time.Duration
+}
+
+func newEventTracker(maxAge time.Duration) *eventTracker {
+	return &eventTracker{
+		events: make(map[interface{}][]*trackedEvent),
+		maxAge: maxAge,
+	}
+}
+
+// Add adds a event to the history of key, discarding the expired ones while at it
+func (t *eventTracker) Add(key interface{}, evt *trackedEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events[key] = append(t.pruned(key, time.Now()), evt)
+}
+
+// Since returns all events for key that happened after since, oldest first
+func (t *eventTracker) Since(key interface{}, since time.Time) []*trackedEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := t.events[key]
+
+	// new events are at the end
+	i := len(events)
+	for i > 0 && events[i-1].At.After(since) {
+		i--
+	}
+
+	result := make([]*trackedEvent, len(events)-i)
+	copy(result, events[i:])
+	return result
+}
+
+// pruned returns the events for key with the expired ones removed, t.mu needs to be held
+func (t *eventTracker) pruned(key interface{}, now time.Time) []*trackedEvent {
+	events := t.events[key]
+
+	i := 0
+	for i < len(events) && now.Sub(events[i].At) > t.maxAge {
+		i++
+	}
+
+	return events[i:]
+}
+
+// runCleaner periodically removes expired events so that keys that are no longer active don't stick around forever
+func (t *eventTracker) runCleaner(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for {
+		now := <-ticker.C
+
+		t.mu.Lock()
+		for k := range t.events {
+			events := t.pruned(k, now)
+			if len(events) < 1 {
+				delete(t.events, k)
+			} else {
+				t.events[k] = events
+			}
+		}
+		t.mu.Unlock()
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"github.com/jonas747/dstate"
 	"github.com/jonas747/yagpdb/automod/models"
 	"github.com/jonas747/yagpdb/automod_legacy"
+	"github.com/jonas747/yagpdb/bot"
 	"github.com/jonas747/yagpdb/common"
 	"github.com/jonas747/yagpdb/safebrowsing"
 	"github.com/sirupsen/logrus"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 )
@@ -1337,3 +1340,763 @@ func (mat *MessageAttachmentTrigger) CheckMessage(ms *dstate.MemberState, cs *ds
 func (mat *MessageAttachmentTrigger) MergeDuplicates(data []interface{}) interface{} {
 	return data[0] // no point in having duplicates of this
 }
+
+/////////////////////////////////////////////////////////////
+
+type ReactionSpamTriggerData struct {
+	Treshold int
+	Interval int
+}
+
+var _ ReactionListener = (*ReactionSpamTrigger)(nil)
+
+type ReactionSpamTrigger struct{}
+
+func (rs *ReactionSpamTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (rs *ReactionSpamTrigger) DataType() interface{} {
+	return &ReactionSpamTriggerData{}
+}
+
+func (rs *ReactionSpamTrigger) Name() string {
+	return "x user reactions in y seconds"
+}
+
+func (rs *ReactionSpamTrigger) Description() string {
+	return "Triggers when a user has added more than x reactions within y seconds, across all channels"
+}
+
+func (rs *ReactionSpamTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name:    "Reactions",
+			Key:     "Treshold",
+			Kind:    SettingTypeInt,
+			Min:     1,
+			Max:     1000,
+			Default: 10,
+		},
+		&SettingDef{
+			Name:    "Within (seconds)",
+			Key:     "Interval",
+			Kind:    SettingTypeInt,
+			Min:     1,
+			Max:     3600,
+			Default: 10,
+		},
+	}
+}
+
+func (rs *ReactionSpamTrigger) CheckReaction(ms *dstate.MemberState, cs *dstate.ChannelState, reaction *discordgo.MessageReaction, data interface{}) (bool, error) {
+	settings := data.(*ReactionSpamTriggerData)
+
+	since := time.Now().Add(-time.Duration(settings.Interval) * time.Second)
+	recent := reactionTracker.Since(reactionTrackerKey{GuildID: ms.Guild.ID, UserID: ms.ID}, since)
+
+	if len(recent) >= settings.Treshold {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (rs *ReactionSpamTrigger) MergeDuplicates(data []interface{}) interface{} {
+	return data[0] // no point in having duplicates of this
+}
+
+/////////////////////////////////////////////////////////////
+
+var _ ReactionListener = (*ReactionEmojiTrigger)(nil)
+
+type ReactionEmojiTrigger struct {
+	Blacklist bool
+}
+
+type ReactionEmojiTriggerData struct {
+	ListID int64
+}
+
+func (re *ReactionEmojiTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (re *ReactionEmojiTrigger) DataType() interface{} {
+	return &ReactionEmojiTriggerData{}
+}
+
+func (re *ReactionEmojiTrigger) Name() string {
+	if re.Blacklist {
+		return "Reaction emoji blacklist"
+	}
+
+	return "Reaction emoji whitelist"
+}
+
+func (re *ReactionEmojiTrigger) Description() string {
+	if re.Blacklist {
+		return "Triggers when a user reacts with a emoji in the specified list, the list can contain unicode emojis, custom emoji names and custom emoji ID's"
+	}
+
+	return "Triggers when a user reacts with a emoji NOT in the specified list, the list can contain unicode emojis, custom emoji names and custom emoji ID's"
+}
+
+func (re *ReactionEmojiTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name: "List",
+			Key:  "ListID",
+			Kind: SettingTypeList,
+		},
+	}
+}
+
+func (re *ReactionEmojiTrigger) CheckReaction(ms *dstate.MemberState, cs *dstate.ChannelState, reaction *discordgo.MessageReaction, data interface{}) (bool, error) {
+	dataCast := data.(*ReactionEmojiTriggerData)
+
+	list, err := FindFetchGuildList(ms.Guild, dataCast.ListID)
+	if err != nil {
+		return false, nil
+	}
+
+	contained := false
+	for _, v := range list.Content {
+		if EmojiMatches(&reaction.Emoji, v) {
+			contained = true
+			break
+		}
+	}
+
+	if re.Blacklist {
+		return contained, nil
+	}
+
+	return !contained, nil
+}
+
+// EmojiMatches returns true if the emoji matches the provided list entry,
+// which can either be a unicode emoji, a custom emoji name (optionally surrounded by colons), a custom emoji ID or a custom emoji in the <:name:id> format
+func EmojiMatches(emoji *discordgo.Emoji, entry string) bool {
+	if emoji.ID != 0 {
+		idStr := strconv.FormatInt(emoji.ID, 10)
+		if entry == idStr || strings.HasSuffix(entry, ":"+idStr+">") {
+			return true
+		}
+	}
+
+	return strings.EqualFold(strings.Trim(entry, ":"), emoji.Name)
+}
+
+/////////////////////////////////////////////////////////////
+
+type ReactionAccountAgeTriggerData struct {
+	Treshold int
+}
+
+var _ ReactionListener = (*ReactionAccountAgeTrigger)(nil)
+
+type ReactionAccountAgeTrigger struct{}
+
+func (ra *ReactionAccountAgeTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (ra *ReactionAccountAgeTrigger) DataType() interface{} {
+	return &ReactionAccountAgeTriggerData{}
+}
+
+func (ra *ReactionAccountAgeTrigger) Name() string {
+	return "Reaction from new account"
+}
+
+func (ra *ReactionAccountAgeTrigger) Description() string {
+	return "Triggers when a user with an account younger than x minutes adds a reaction"
+}
+
+func (ra *ReactionAccountAgeTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name:    "Account age (minutes)",
+			Key:     "Treshold",
+			Kind:    SettingTypeInt,
+			Default: 1440,
+		},
+	}
+}
+
+func (ra *ReactionAccountAgeTrigger) CheckReaction(ms *dstate.MemberState, cs *dstate.ChannelState, reaction *discordgo.MessageReaction, data interface{}) (bool, error) {
+	settings := data.(*ReactionAccountAgeTriggerData)
+
+	created := bot.SnowflakeToTime(ms.ID)
+	if int(time.Since(created).Minutes()) <= settings.Treshold {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (ra *ReactionAccountAgeTrigger) MergeDuplicates(data []interface{}) interface{} {
+	return data[0] // no point in having duplicates of this
+}
+
+/////////////////////////////////////////////////////////////
+
+type CrossChannelSpamTriggerData struct {
+	Treshold   int
+	TimeLimit  int
+	Similarity int
+}
+
+var _ MessageTrigger = (*CrossChannelSpamTrigger)(nil)
+
+type CrossChannelSpamTrigger struct{}
+
+func (spam *CrossChannelSpamTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (spam *CrossChannelSpamTrigger) DataType() interface{} {
+	return &CrossChannelSpamTriggerData{}
+}
+
+func (spam *CrossChannelSpamTrigger) Name() string {
+	return "x similar messages across channels"
+}
+
+func (spam *CrossChannelSpamTrigger) Description() string {
+	return "Triggers when a user sends the same or similar messages in x different channels within y seconds, ignoring case, spaces and punctuation"
+}
+
+func (spam *CrossChannelSpamTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name:    "Channels",
+			Key:     "Treshold",
+			Kind:    SettingTypeInt,
+			Min:     2,
+			Max:     100,
+			Default: 3,
+		},
+		&SettingDef{
+			Name:    "Within seconds",
+			Key:     "TimeLimit",
+			Kind:    SettingTypeInt,
+			Min:     1,
+			Max:     3600,
+			Default: 60,
+		},
+		&SettingDef{
+			Name:    "Similarity (percent, 100 = identical)",
+			Key:     "Similarity",
+			Kind:    SettingTypeInt,
+			Min:     1,
+			Max:     100,
+			Default: 90,
+		},
+	}
+}
+
+func (spam *CrossChannelSpamTrigger) CheckMessage(ms *dstate.MemberState, cs *dstate.ChannelState, m *discordgo.Message, mdStripped string, data interface{}) (bool, error) {
+	settingsCast := data.(*CrossChannelSpamTriggerData)
+
+	normalized := NormalizeForSimilarity(m.Content)
+	if normalized == "" {
+		return false, nil // e.g only attachments or emojis
+	}
+
+	timeLimit := time.Now().Add(-time.Second * time.Duration(settingsCast.TimeLimit))
+
+	// the channel the message was sent in counts as one
+	channels := []int64{cs.ID}
+
+	gs := cs.Guild
+	gs.RLock()
+	defer gs.RUnlock()
+
+	for _, c := range gs.Channels {
+		if c.ID == cs.ID {
+			continue
+		}
+
+		// New messages are at the end
+		for i := len(c.Messages) - 1; i >= 0; i-- {
+			cMsg := c.Messages[i]
+
+			if timeLimit.After(cMsg.ParsedCreated) {
+				break
+			}
+
+			if cMsg.Author.ID != ms.ID || cMsg.Deleted {
+				continue
+			}
+
+			if Similarity(normalized, NormalizeForSimilarity(cMsg.Content)) >= settingsCast.Similarity {
+				channels = append(channels, c.ID)
+				break
+			}
+		}
+
+		if len(channels) >= settingsCast.Treshold {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (spam *CrossChannelSpamTrigger) MergeDuplicates(data []interface{}) interface{} {
+	return data[0] // no point in having duplicates of this
+}
+
+/////////////////////////////////////////////////////////////
+
+type RaidTriggerData struct {
+	Treshold          int
+	Interval          int
+	MaxAccountAge     int
+	OnlyDefaultAvatar bool
+	AffectBurst       bool
+}
+
+var _ JoinListener = (*RaidTrigger)(nil)
+var _ BurstTrigger = (*RaidTrigger)(nil)
+
+type RaidTrigger struct{}
+
+func (rt *RaidTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (rt *RaidTrigger) DataType() interface{} {
+	return &RaidTriggerData{}
+}
+
+func (rt *RaidTrigger) Name() string {
+	return "x joins in y seconds (raid)"
+}
+
+func (rt *RaidTrigger) Description() string {
+	return "Triggers on every member that joins while more than x members (matching the filters) has joined within y seconds, optionally also applying the effects to everyone else that joined in that time"
+}
+
+func (rt *RaidTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name:    "Joins",
+			Key:     "Treshold",
+			Kind:    SettingTypeInt,
+			Min:     2,
+			Max:     1000,
+			Default: 10,
+		},
+		&SettingDef{
+			Name:    "Within (seconds)",
+			Key:     "Interval",
+			Kind:    SettingTypeInt,
+			Min:     1,
+			Max:     3600,
+			Default: 30,
+		},
+		&SettingDef{
+			Name:    "Only count accounts younger than (minutes, 0 for all)",
+			Key:     "MaxAccountAge",
+			Kind:    SettingTypeInt,
+			Min:     0,
+			Max:     5256000, // 10 years
+			Default: 0,
+		},
+		&SettingDef{
+			Name: "Only count members without an avatar",
+			Key:  "OnlyDefaultAvatar",
+			Kind: SettingTypeBool,
+		},
+		&SettingDef{
+			Name:    "Also apply the effects to all members that joined within the time frame",
+			Key:     "AffectBurst",
+			Kind:    SettingTypeBool,
+			Default: true,
+		},
+	}
+}
+
+func (rt *RaidTrigger) matchesFilters(settings *RaidTriggerData, user *discordgo.User) bool {
+	if settings.OnlyDefaultAvatar && user.Avatar != "" {
+		return false
+	}
+
+	if settings.MaxAccountAge > 0 {
+		created := bot.SnowflakeToTime(user.ID)
+		if time.Since(created) > time.Duration(settings.MaxAccountAge)*time.Minute {
+			return false
+		}
+	}
+
+	return true
+}
+
+// burst returns the members that joined within the interval and matches the filters
+func (rt *RaidTrigger) burst(guildID int64, settings *RaidTriggerData) []*trackedEvent {
+	since := time.Now().Add(-time.Duration(settings.Interval) * time.Second)
+	joins := joinTracker.Since(guildID, since)
+
+	filtered := make([]*trackedEvent, 0, len(joins))
+	for _, v := range joins {
+		if rt.matchesFilters(settings, v.Data.(*discordgo.User)) {
+			filtered = append(filtered, v)
+		}
+	}
+
+	return filtered
+}
+
+func (rt *RaidTrigger) CheckJoin(ms *dstate.MemberState, data interface{}) (bool, error) {
+	settings := data.(*RaidTriggerData)
+
+	// don't punish members that dont match the filters, even if a raid is going on
+	if !rt.matchesFilters(settings, ms.DGoUser()) {
+		return false, nil
+	}
+
+	if len(rt.burst(ms.Guild.ID, settings)) >= settings.Treshold {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// raidHandledTracker keeps track of the members the effects of a raid rule has already been applied to, keyed by raidHandledKey
+var raidHandledTracker = newEventTracker(time.Hour)
+var raidHandledMU sync.Mutex
+
+type raidHandledKey struct {
+	GuildID int64
+	RuleID  int64
+}
+
+func (rt *RaidTrigger) BurstMembers(ctxData *TriggeredRuleData, data interface{}) ([]int64, error) {
+	settings := data.(*RaidTriggerData)
+
+	key := raidHandledKey{GuildID: ctxData.GS.ID, RuleID: ctxData.CurrentRule.Model.ID}
+	now := time.Now()
+
+	raidHandledMU.Lock()
+	defer raidHandledMU.Unlock()
+
+	handled := raidHandledTracker.Since(key, now.Add(-time.Hour))
+	isHandled := func(userID int64) bool {
+		for _, v := range handled {
+			if v.UserID == userID {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// the member that activated the trigger is handled as normal
+	if !isHandled(ctxData.MS.ID) {
+		raidHandledTracker.Add(key, &trackedEvent{At: now, UserID: ctxData.MS.ID})
+	}
+
+	if !settings.AffectBurst {
+		return nil, nil
+	}
+
+	var result []int64
+	for _, v := range rt.burst(ctxData.GS.ID, settings) {
+		if v.UserID == ctxData.MS.ID || isHandled(v.UserID) {
+			continue
+		}
+
+		raidHandledTracker.Add(key, &trackedEvent{At: now, UserID: v.UserID})
+		result = append(result, v.UserID)
+	}
+
+	return result, nil
+}
+
+func (rt *RaidTrigger) MergeDuplicates(data []interface{}) interface{} {
+	return data[0] // no point in having duplicates of this
+}
+
+/////////////////////////////////////////////////////////////
+
+var _ MessageTrigger = (*AttachmentExtensionTrigger)(nil)
+
+type AttachmentExtensionTrigger struct {
+	Blacklist bool
+}
+
+type AttachmentExtensionTriggerData struct {
+	ListID int64
+}
+
+func (ae *AttachmentExtensionTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (ae *AttachmentExtensionTrigger) DataType() interface{} {
+	return &AttachmentExtensionTriggerData{}
+}
+
+func (ae *AttachmentExtensionTrigger) Name() string {
+	if ae.Blacklist {
+		return "Attachment file type blacklist"
+	}
+
+	return "Attachment file type whitelist"
+}
+
+func (ae *AttachmentExtensionTrigger) Description() string {
+	if ae.Blacklist {
+		return "Triggers on attachments with a file extension in the specified list (e.g exe, scr, bat)"
+	}
+
+	return "Triggers on attachments with a file extension NOT in the specified list (e.g png, jpg, gif), files without a extension also trigger this"
+}
+
+func (ae *AttachmentExtensionTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name: "List",
+			Key:  "ListID",
+			Kind: SettingTypeList,
+		},
+	}
+}
+
+func (ae *AttachmentExtensionTrigger) CheckMessage(ms *dstate.MemberState, cs *dstate.ChannelState, m *discordgo.Message, mdStripped string, data interface{}) (bool, error) {
+	if len(m.Attachments) < 1 {
+		return false, nil
+	}
+
+	dataCast := data.(*AttachmentExtensionTriggerData)
+
+	list, err := FindFetchGuildList(cs.Guild, dataCast.ListID)
+	if err != nil {
+		return false, nil
+	}
+
+	for _, attachment := range m.Attachments {
+		ext := AttachmentExtension(attachment.Filename)
+
+		contained := false
+		for _, v := range list.Content {
+			if strings.EqualFold(strings.TrimPrefix(v, "."), ext) {
+				contained = true
+				break
+			}
+		}
+
+		if contained == ae.Blacklist {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// bidiControlReplacer removes the unicode direction control characters, which are used to disguise the real extension of a file
+// (e.g "photo\u202egnp.exe" is displayed as "photoexe.png")
+var bidiControlReplacer = strings.NewReplacer(
+	"\u202a", "", "\u202b", "", "\u202c", "", "\u202d", "", "\u202e", "",
+	"\u2066", "", "\u2067", "", "\u2068", "", "\u2069", "",
+	"\u200e", "", "\u200f", "",
+)
+
+// AttachmentExtension returns the real extension of the file in lowercase without the leading dot, or a empty string if it has none
+func AttachmentExtension(filename string) string {
+	filename = bidiControlReplacer.Replace(filename)
+
+	// windows ignores trailing dots and spaces, so "virus.exe. " is still a executable
+	filename = strings.TrimRight(filename, ". ")
+
+	index := strings.LastIndex(filename, ".")
+	if index == -1 {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(filename[index+1:]))
+}
+
+/////////////////////////////////////////////////////////////
+
+var _ MessageTrigger = (*AttachmentSizeTrigger)(nil)
+
+type AttachmentSizeTriggerData struct {
+	MinSize int
+}
+
+type AttachmentSizeTrigger struct{}
+
+func (as *AttachmentSizeTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (as *AttachmentSizeTrigger) DataType() interface{} {
+	return &AttachmentSizeTriggerData{}
+}
+
+func (as *AttachmentSizeTrigger) Name() string {
+	return "Large attachments"
+}
+
+func (as *AttachmentSizeTrigger) Description() string {
+	return "Triggers on attachments larger than the specified size"
+}
+
+func (as *AttachmentSizeTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name:    "Size in KB",
+			Key:     "MinSize",
+			Kind:    SettingTypeInt,
+			Min:     1,
+			Max:     512000,
+			Default: 8192,
+		},
+	}
+}
+
+func (as *AttachmentSizeTrigger) CheckMessage(ms *dstate.MemberState, cs *dstate.ChannelState, m *discordgo.Message, mdStripped string, data interface{}) (bool, error) {
+	dataCast := data.(*AttachmentSizeTriggerData)
+
+	for _, v := range m.Attachments {
+		if v.Size > dataCast.MinSize*1024 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+/////////////////////////////////////////////////////////////
+
+var _ MessageTrigger = (*SpoilerAttachmentTrigger)(nil)
+
+type SpoilerAttachmentTrigger struct{}
+
+func (sa *SpoilerAttachmentTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (sa *SpoilerAttachmentTrigger) DataType() interface{} {
+	return nil
+}
+
+func (sa *SpoilerAttachmentTrigger) Name() string {
+	return "Spoiler attachments"
+}
+
+func (sa *SpoilerAttachmentTrigger) Description() string {
+	return "Triggers on attachments marked as spoilers"
+}
+
+func (sa *SpoilerAttachmentTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{}
+}
+
+func (sa *SpoilerAttachmentTrigger) CheckMessage(ms *dstate.MemberState, cs *dstate.ChannelState, m *discordgo.Message, mdStripped string, data interface{}) (bool, error) {
+	for _, v := range m.Attachments {
+		if strings.HasPrefix(v.Filename, "SPOILER_") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (sa *SpoilerAttachmentTrigger) MergeDuplicates(data []interface{}) interface{} {
+	return data[0] // no point in having duplicates of this
+}
+
+/////////////////////////////////////////////////////////////
+
+var _ MessageTrigger = (*EmbedDomainTrigger)(nil)
+
+// EmbedDomainTrigger checks the urls of the link previews discord generates, these are usually added in a later message update
+// so unlike other triggers it's also checked when the embeds are added
+type EmbedDomainTrigger struct {
+	Blacklist bool
+}
+
+type EmbedDomainTriggerData struct {
+	ListID int64
+}
+
+func (ed *EmbedDomainTrigger) Kind() RulePartType {
+	return RulePartTrigger
+}
+
+func (ed *EmbedDomainTrigger) DataType() interface{} {
+	return &EmbedDomainTriggerData{}
+}
+
+func (ed *EmbedDomainTrigger) Name() string {
+	if ed.Blacklist {
+		return "Link preview website blacklist"
+	}
+
+	return "Link preview website whitelist"
+}
+
+func (ed *EmbedDomainTrigger) Description() string {
+	if ed.Blacklist {
+		return "Triggers on link previews (embeds) pointing to websites in the specified list, this includes redirects that the message content doesn't show"
+	}
+
+	return "Triggers on link previews (embeds) pointing to websites NOT in the specified list, this includes redirects that the message content doesn't show"
+}
+
+func (ed *EmbedDomainTrigger) UserSettings() []*SettingDef {
+	return []*SettingDef{
+		&SettingDef{
+			Name: "List",
+			Key:  "ListID",
+			Kind: SettingTypeList,
+		},
+	}
+}
+
+func (ed *EmbedDomainTrigger) CheckMessage(ms *dstate.MemberState, cs *dstate.ChannelState, m *discordgo.Message, mdStripped string, data interface{}) (bool, error) {
+	if len(m.Embeds) < 1 {
+		return false, nil
+	}
+
+	dataCast := data.(*EmbedDomainTriggerData)
+
+	list, err := FindFetchGuildList(cs.Guild, dataCast.ListID)
+	if err != nil {
+		return false, nil
+	}
+
+	domainTrigger := &DomainTrigger{Blacklist: ed.Blacklist}
+	for _, embed := range m.Embeds {
+		for _, link := range embedURLs(embed) {
+			if contains, _ := domainTrigger.containsDomain(link, list.Content); contains == ed.Blacklist {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// embedURLs returns all the urls in the embed that point to a website
+func embedURLs(embed *discordgo.MessageEmbed) []string {
+	var result []string
+	if embed.URL != "" {
+		result = append(result, embed.URL)
+	}
+
+	if embed.Provider != nil && embed.Provider.URL != "" {
+		result = append(result, embed.Provider.URL)
+	}
+
+	if embed.Author != nil && embed.Author.URL != "" {
+		result = append(result, embed.Author.URL)
+	}
+
+	return result
+}
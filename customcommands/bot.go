@@ -54,6 +54,12 @@ func (p *Plugin) AddCommands() {
 
 func (p *Plugin) BotInit() {
 	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleMessageCreate), eventsystem.EventMessageCreate)
+	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleMessageReactionAddRemove), eventsystem.EventMessageReactionAdd, eventsystem.EventMessageReactionRemove)
+	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleGuildMemberAdd), eventsystem.EventGuildMemberAdd)
+
+	// these needs the state before it's updated to know the roles of the member
+	eventsystem.AddHandlerBefore(HandleGuildMemberRemove, eventsystem.EventGuildMemberRemove, bot.StateHandlerPtr)
+	eventsystem.AddHandlerBefore(HandleGuildMemberUpdate, eventsystem.EventGuildMemberUpdate, bot.StateHandlerPtr)
 
 	// add the pubsub handler for cache eviction
 	pubsub.AddHandler("custom_commands_clear_cache", func(event *pubsub.Event) {
@@ -63,6 +69,8 @@ func (p *Plugin) BotInit() {
 		}
 
 		gs.UserCacheDel(true, CacheKeyCommands)
+		gs.UserCacheDel(true, CacheKeyReactionCommands)
+		gs.UserCacheDel(true, CacheKeyMemberCommands)
 	}, nil)
 
 	scheduledevents2.RegisterHandler("cc_next_run", NextRunScheduledEvent{}, handleNextRunScheduledEVent)
@@ -353,6 +361,132 @@ func HandleMessageCreate(evt *eventsystem.EventData) {
 	}
 }
 
+func HandleMessageReactionAddRemove(evt *eventsystem.EventData) {
+	var reaction *discordgo.MessageReaction
+	added := false
+	if evt.Type == eventsystem.EventMessageReactionAdd {
+		reaction = evt.MessageReactionAdd().MessageReaction
+		added = true
+	} else {
+		reaction = evt.MessageReactionRemove().MessageReaction
+	}
+
+	if reaction.GuildID == 0 || reaction.UserID == common.BotUser.ID {
+		return
+	}
+
+	cs := bot.State.Channel(true, reaction.ChannelID)
+	if cs == nil || cs.Guild == nil || cs.IsPrivate {
+		return
+	}
+
+	if !bot.BotProbablyHasPermissionGS(true, cs.Guild, cs.ID, discordgo.PermissionSendMessages) {
+		return
+	}
+
+	cmds, err := BotCachedGetCommandsWithReactionTriggers(cs.Guild, evt.Context())
+	if err != nil {
+		log.WithError(err).WithField("guild", cs.Guild.ID).Error("Failed retrieving comamnds")
+		return
+	}
+
+	if len(cmds) < 1 {
+		return
+	}
+
+	member, err := bot.GetMember(cs.Guild.ID, reaction.UserID)
+	if err != nil || member == nil || member.Bot {
+		return
+	}
+
+	var matched *models.CustomCommand
+	for _, cmd := range cmds {
+		if !CmdRunsInChannel(cmd, reaction.ChannelID) || !CmdRunsForUser(cmd, member) {
+			continue
+		}
+
+		if CheckReactionMatch(cmd, reaction, added) {
+			matched = cmd
+			break
+		}
+	}
+
+	if matched == nil || len(matched.Responses) == 0 {
+		return
+	}
+
+	// we only have the message id, so fetch the message the reaction is on
+	msg, err := common.BotSession.ChannelMessage(reaction.ChannelID, reaction.MessageID)
+	if err != nil {
+		log.WithError(err).WithField("guild", cs.Guild.ID).Error("Failed retrieving reaction message")
+		return
+	}
+	msg.GuildID = cs.Guild.ID
+
+	if common.Statsd != nil {
+		go common.Statsd.Incr("yagpdb.cc.executed", nil, 1)
+	}
+
+	err = ExecuteCustomCommandFromReaction(matched, member, cs, reaction, added, msg)
+	if err != nil {
+		log.WithField("guild", cs.Guild.ID).WithError(err).Error("Error executing custom command")
+	}
+}
+
+func ExecuteCustomCommandFromReaction(cmd *models.CustomCommand, member *dstate.MemberState, cs *dstate.ChannelState, reaction *discordgo.MessageReaction, added bool, m *discordgo.Message) error {
+	tmplCtx := templates.NewContext(cs.Guild, cs, member)
+
+	// commands ran through exec should be ran as the user reacting, not the author of the message
+	fakeMsg := *m
+	fakeMsg.Author = member.DGoUser()
+	tmplCtx.Msg = &fakeMsg
+
+	tmplCtx.Data["Reaction"] = reaction
+	tmplCtx.Data["ReactionMessage"] = m
+	tmplCtx.Data["ReactionAdded"] = added
+	tmplCtx.Data["Message"] = m
+
+	return ExecuteCustomCommand(cmd, tmplCtx)
+}
+
+// CheckReactionMatch returns true if the reaction command should run for the reaction
+func CheckReactionMatch(cmd *models.CustomCommand, reaction *discordgo.MessageReaction, added bool) bool {
+	if cmd.TriggerType != int(CommandTriggerReaction) || !ReactionMode(cmd.ReactionTriggerMode).Matches(added) {
+		return false
+	}
+
+	if len(cmd.ReactionTriggerMessages) > 0 && !common.ContainsInt64Slice(cmd.ReactionTriggerMessages, reaction.MessageID) {
+		return false
+	}
+
+	emojis := strings.Fields(cmd.TextTrigger)
+	if len(emojis) < 1 {
+		// no emoji restrictions
+		return true
+	}
+
+	for _, v := range emojis {
+		if reactionEmojiMatches(&reaction.Emoji, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reactionEmojiMatches returns true if the emoji matches the entry, which can either be a unicode emoji,
+// a custom emoji name (optionally surrounded by colons), a custom emoji ID or a custom emoji in the <:name:id> format
+func reactionEmojiMatches(emoji *discordgo.Emoji, entry string) bool {
+	if emoji.ID != 0 {
+		idStr := strconv.FormatInt(emoji.ID, 10)
+		if entry == idStr || strings.HasSuffix(entry, ":"+idStr+">") {
+			return true
+		}
+	}
+
+	return strings.EqualFold(strings.Trim(entry, ":"), emoji.Name)
+}
+
 func ExecuteCustomCommandFromMessage(cmd *models.CustomCommand, member *dstate.MemberState, cs *dstate.ChannelState, cmdArgs []string, stripped string, m *discordgo.Message) error {
 	tmplCtx := templates.NewContext(cs.Guild, cs, member)
 	tmplCtx.Msg = m
@@ -543,11 +677,37 @@ type CacheKey int
 const (
 	CacheKeyCommands CacheKey = iota
 	CacheKeyDBLimits
+	CacheKeyReactionCommands
+	CacheKeyMemberCommands
 )
 
 func BotCachedGetCommandsWithMessageTriggers(gs *dstate.GuildState, ctx context.Context) ([]*models.CustomCommand, error) {
 	v, err := gs.UserCacheFetch(true, CacheKeyCommands, func() (interface{}, error) {
-		return models.CustomCommands(qm.Where("guild_id = ? AND trigger_type != 5", gs.Guild.ID), qm.OrderBy("local_id desc"), qm.Load("Group")).AllG(ctx)
+		return models.CustomCommands(qm.Where("guild_id = ? AND trigger_type IN (0, 1, 2, 3, 4)", gs.Guild.ID), qm.OrderBy("local_id desc"), qm.Load("Group")).AllG(ctx)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(models.CustomCommandSlice), nil
+}
+
+func BotCachedGetCommandsWithReactionTriggers(gs *dstate.GuildState, ctx context.Context) ([]*models.CustomCommand, error) {
+	v, err := gs.UserCacheFetch(true, CacheKeyReactionCommands, func() (interface{}, error) {
+		return models.CustomCommands(qm.Where("guild_id = ? AND trigger_type = 6", gs.Guild.ID), qm.OrderBy("local_id desc"), qm.Load("Group")).AllG(ctx)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(models.CustomCommandSlice), nil
+}
+
+func BotCachedGetCommandsWithMemberTriggers(gs *dstate.GuildState, ctx context.Context) ([]*models.CustomCommand, error) {
+	v, err := gs.UserCacheFetch(true, CacheKeyMemberCommands, func() (interface{}, error) {
+		return models.CustomCommands(qm.Where("guild_id = ? AND trigger_type IN (7, 8, 9, 10)", gs.Guild.ID), qm.OrderBy("local_id asc"), qm.Load("Group")).AllG(ctx)
 	})
 
 	if err != nil {
@@ -94,3 +94,60 @@ func TestCheckMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckReactionMatch(t *testing.T) {
+	thumbsUp := &discordgo.MessageReaction{MessageID: 1, Emoji: discordgo.Emoji{Name: "👍"}}
+	custom := &discordgo.MessageReaction{MessageID: 2, Emoji: discordgo.Emoji{Name: "yag", ID: 1234}}
+
+	tests := []struct {
+		cmd      *models.CustomCommand
+		reaction *discordgo.MessageReaction
+		added    bool
+		match    bool
+	}{
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction)}, thumbsUp, true, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction)}, thumbsUp, false, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerCommand)}, thumbsUp, true, false},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), ReactionTriggerMode: int(ReactionModeAddOnly)}, thumbsUp, false, false},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), ReactionTriggerMode: int(ReactionModeRemoveOnly)}, thumbsUp, false, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), TextTrigger: "👎 👍"}, thumbsUp, true, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), TextTrigger: "👎"}, thumbsUp, true, false},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), TextTrigger: ":yag:"}, custom, true, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), TextTrigger: "<:other:1234>"}, custom, true, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), TextTrigger: "4321"}, custom, true, false},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), ReactionTriggerMessages: []int64{2, 3}}, thumbsUp, true, false},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerReaction), ReactionTriggerMessages: []int64{2, 3}}, custom, true, true},
+	}
+
+	for i, test := range tests {
+		m := CheckReactionMatch(test.cmd, test.reaction, test.added)
+		if m != test.match {
+			t.Errorf("%d: got match '%t', want match '%t'", i, m, test.match)
+		}
+	}
+}
+
+func TestCheckMemberTriggerMatch(t *testing.T) {
+	role := &discordgo.Role{ID: 10}
+
+	tests := []struct {
+		cmd         *models.CustomCommand
+		triggerType CommandTriggerType
+		role        *discordgo.Role
+		match       bool
+	}{
+		{&models.CustomCommand{TriggerType: int(CommandTriggerMemberJoin)}, CommandTriggerMemberJoin, nil, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerMemberJoin)}, CommandTriggerMemberLeave, nil, false},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerRoleAdded)}, CommandTriggerRoleAdded, role, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerRoleAdded)}, CommandTriggerRoleRemoved, role, false},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerRoleRemoved), TriggerRoles: []int64{5, 10}}, CommandTriggerRoleRemoved, role, true},
+		{&models.CustomCommand{TriggerType: int(CommandTriggerRoleRemoved), TriggerRoles: []int64{5}}, CommandTriggerRoleRemoved, role, false},
+	}
+
+	for i, test := range tests {
+		m := CheckMemberTriggerMatch(test.cmd, test.triggerType, test.role)
+		if m != test.match {
+			t.Errorf("%d: got match '%t', want match '%t'", i, m, test.match)
+		}
+	}
+}
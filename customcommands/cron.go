@@ -0,0 +1,277 @@
+package customcommands
+
+import (
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed standard 5 field cron expression: minute, hour, day of month, month and day of week
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// if either of the day fields is a wildcard both have to match, otherwise only one of them has to (like regular cron)
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is also sunday
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// ParseCronSchedule parses a cron expression, e.g "0 18 * * FRI", or one of the macros like @daily
+func ParseCronSchedule(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.New("cron expression needs 5 fields: minute, hour, day of month, month and day of week")
+	}
+
+	var err error
+	schedule := &CronSchedule{
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+
+	if schedule.minute, err = cronMinute.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = cronHour.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = cronDom.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = cronMonth.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = cronDow.parse(fields[4]); err != nil {
+		return nil, err
+	}
+
+	// fold 7 into sunday
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+
+	return schedule, nil
+}
+
+// parse parses a comma separated list of values, ranges and steps (e.g "1,5-10,*/15") into a bitset
+func (f cronField) parse(str string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(str, ",") {
+		rangePart := part
+		step := 1
+		if i := strings.Index(part, "/"); i != -1 {
+			rangePart = part[:i]
+
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return 0, errors.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			var err error
+			if i := strings.Index(rangePart, "-"); i != -1 {
+				if low, err = f.parseValue(rangePart[:i]); err != nil {
+					return 0, err
+				}
+				if high, err = f.parseValue(rangePart[i+1:]); err != nil {
+					return 0, err
+				}
+			} else {
+				if low, err = f.parseValue(rangePart); err != nil {
+					return 0, err
+				}
+
+				// "5/10" means every 10th starting at 5
+				high = low
+				if step > 1 {
+					high = f.max
+				}
+			}
+		}
+
+		if low > high {
+			return 0, errors.Errorf("invalid range in %s field: %q", f.name, part)
+		}
+
+		for i := low; i <= high; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func (f cronField) parseValue(str string) (int, error) {
+	if v, ok := f.names[strings.ToLower(str)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(str)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.Errorf("invalid value in %s field: %q (should be between %d and %d)", f.name, str, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// Max number of steps Next takes before giving up, a safety net in case some timezone transition keeps it from moving forward
+const cronMaxSteps = 100000
+
+// Next returns the next time after t the schedule runs, in the location of t.
+// A zero time is returned if it never runs within the next 5 years (e.g "0 0 30 2 *")
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + 5
+	steps := 0
+
+WRAP:
+	if t.Year() > yearLimit || steps > cronMaxSteps {
+		return time.Time{}
+	}
+
+	for c.month&(1<<uint(t.Month())) == 0 {
+		steps++
+		year := t.Year()
+		t = cronStepTo(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		if t.Year() != year {
+			goto WRAP
+		}
+	}
+
+	for !c.dayMatches(t) {
+		steps++
+		month := t.Month()
+		t = cronStepTo(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		if t.Month() != month {
+			goto WRAP
+		}
+	}
+
+	for c.hour&(1<<uint(t.Hour())) == 0 {
+		steps++
+		day := t.Day()
+		t = cronNextHour(t)
+		if t.Day() != day {
+			goto WRAP
+		}
+	}
+
+	for c.minute&(1<<uint(t.Minute())) == 0 {
+		steps++
+		hour := t.Hour()
+		t = t.Add(time.Minute)
+		if t.Hour() != hour {
+			goto WRAP
+		}
+	}
+
+	return t
+}
+
+// cronStepTo returns next if it's after t, otherwise the start of the next hour.
+// time.Date normalizes times skipped by daylight saving changes, in some timezones (e.g America/Santiago, where it starts at midnight)
+// that can move it backwards.
+func cronStepTo(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+
+	return cronNextHour(t)
+}
+
+// cronNextHour returns the start of the next hour in the location of t, stepping in absolute time so it always moves forward
+func cronNextHour(t time.Time) time.Time {
+	t = t.Add(time.Hour)
+	return t.Add(-time.Duration(t.Minute()) * time.Minute)
+}
+
+// NextN returns the next n run times after t
+func (c *CronSchedule) NextN(t time.Time, n int) []time.Time {
+	result := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		t = c.Next(t)
+		if t.IsZero() {
+			break
+		}
+
+		result = append(result, t)
+	}
+
+	return result
+}
+
+// MinInterval returns the shortest time between the next n runs after t, used to apply the interval limits to cron schedules
+func (c *CronSchedule) MinInterval(t time.Time, n int) time.Duration {
+	runs := c.NextN(t, n)
+
+	var min time.Duration
+	for i := 1; i < len(runs); i++ {
+		d := runs[i].Sub(runs[i-1])
+		if min == 0 || d < min {
+			min = d
+		}
+	}
+
+	return min
+}
+
+// LoadCronLocation returns the location for a IANA timezone name, UTC if empty
+func LoadCronLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.Errorf("unknown timezone %q, use a IANA timezone name like Europe/Berlin", name)
+	}
+
+	return loc, nil
+}
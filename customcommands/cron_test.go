@@ -0,0 +1,127 @@
+package customcommands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no timezone data: ", err)
+	}
+
+	// friday the 27th, daylight saving starts sunday the 29th
+	start := time.Date(2026, 3, 27, 12, 0, 0, 0, berlin)
+
+	tests := []struct {
+		expr string
+		want []time.Time
+	}{
+		{"0 18 * * FRI", []time.Time{
+			time.Date(2026, 3, 27, 18, 0, 0, 0, berlin),
+			time.Date(2026, 4, 3, 18, 0, 0, 0, berlin),
+		}},
+		{"*/20 12 * * *", []time.Time{
+			time.Date(2026, 3, 27, 12, 20, 0, 0, berlin),
+			time.Date(2026, 3, 27, 12, 40, 0, 0, berlin),
+			time.Date(2026, 3, 28, 12, 0, 0, 0, berlin),
+		}},
+		// 02:30 does not exist on the 29th
+		{"30 2 * * *", []time.Time{
+			time.Date(2026, 3, 28, 2, 30, 0, 0, berlin),
+			time.Date(2026, 3, 30, 2, 30, 0, 0, berlin),
+		}},
+		// day of month or day of week when both are set
+		{"0 0 1 * mon", []time.Time{
+			time.Date(2026, 3, 30, 0, 0, 0, 0, berlin),
+			time.Date(2026, 4, 1, 0, 0, 0, 0, berlin),
+			time.Date(2026, 4, 6, 0, 0, 0, 0, berlin),
+		}},
+		{"@monthly", []time.Time{
+			time.Date(2026, 4, 1, 0, 0, 0, 0, berlin),
+		}},
+		{"0 0 30 2 *", []time.Time{}},
+	}
+
+	for _, test := range tests {
+		schedule, err := ParseCronSchedule(test.expr)
+		if err != nil {
+			t.Errorf("%q: failed parsing: %v", test.expr, err)
+			continue
+		}
+
+		got := schedule.NextN(start, len(test.want))
+		if len(got) != len(test.want) {
+			t.Errorf("%q: got %d runs, want %d", test.expr, len(got), len(test.want))
+			continue
+		}
+
+		for i, v := range got {
+			if !v.Equal(test.want[i]) {
+				t.Errorf("%q: run %d: got %s, want %s", test.expr, i, v, test.want[i])
+			}
+		}
+	}
+}
+
+func TestCronScheduleNextMidnightDST(t *testing.T) {
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Skip("no timezone data: ", err)
+	}
+
+	// daylight saving starts at midnight on the 6th, 00:00 - 01:00 does not exist
+	start := time.Date(2026, 9, 5, 13, 0, 0, 0, santiago)
+
+	tests := []struct {
+		expr string
+		want []time.Time
+	}{
+		{"0 12 * * *", []time.Time{
+			time.Date(2026, 9, 6, 12, 0, 0, 0, santiago),
+			time.Date(2026, 9, 7, 12, 0, 0, 0, santiago),
+		}},
+		{"30 0 * * *", []time.Time{
+			time.Date(2026, 9, 7, 0, 30, 0, 0, santiago),
+		}},
+		{"0 * * * *", []time.Time{
+			time.Date(2026, 9, 5, 14, 0, 0, 0, santiago),
+		}},
+		{"0 9 * * sun", []time.Time{
+			time.Date(2026, 9, 6, 9, 0, 0, 0, santiago),
+			time.Date(2026, 9, 13, 9, 0, 0, 0, santiago),
+		}},
+		{"0 0 6 9 *", []time.Time{
+			time.Date(2027, 9, 6, 0, 0, 0, 0, santiago),
+		}},
+	}
+
+	for _, test := range tests {
+		schedule, err := ParseCronSchedule(test.expr)
+		if err != nil {
+			t.Errorf("%q: failed parsing: %v", test.expr, err)
+			continue
+		}
+
+		got := schedule.NextN(start, len(test.want))
+		if len(got) != len(test.want) {
+			t.Errorf("%q: got %d runs, want %d", test.expr, len(got), len(test.want))
+			continue
+		}
+
+		for i, v := range got {
+			if !v.Equal(test.want[i]) {
+				t.Errorf("%q: run %d: got %s, want %s", test.expr, i, v, test.want[i])
+			}
+		}
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := ParseCronSchedule(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
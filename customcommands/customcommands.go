@@ -17,7 +17,10 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/volatiletech/null"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 var (
@@ -57,6 +60,12 @@ const (
 	CommandTriggerExact      CommandTriggerType = 4
 
 	CommandTriggerInterval CommandTriggerType = 5
+	CommandTriggerReaction CommandTriggerType = 6
+
+	CommandTriggerMemberJoin  CommandTriggerType = 7
+	CommandTriggerMemberLeave CommandTriggerType = 8
+	CommandTriggerRoleAdded   CommandTriggerType = 9
+	CommandTriggerRoleRemoved CommandTriggerType = 10
 )
 
 var (
@@ -67,6 +76,11 @@ var (
 		CommandTriggerRegex,
 		CommandTriggerExact,
 		CommandTriggerInterval,
+		CommandTriggerReaction,
+		CommandTriggerMemberJoin,
+		CommandTriggerMemberLeave,
+		CommandTriggerRoleAdded,
+		CommandTriggerRoleRemoved,
 	}
 
 	triggerStrings = map[CommandTriggerType]string{
@@ -76,6 +90,12 @@ var (
 		CommandTriggerRegex:      "Regex",
 		CommandTriggerExact:      "Exact",
 		CommandTriggerInterval:   "Interval",
+		CommandTriggerReaction:   "Reaction",
+
+		CommandTriggerMemberJoin:  "MemberJoin",
+		CommandTriggerMemberLeave: "MemberLeave",
+		CommandTriggerRoleAdded:   "RoleAdded",
+		CommandTriggerRoleRemoved: "RoleRemoved",
 	}
 )
 
@@ -83,6 +103,31 @@ func (t CommandTriggerType) String() string {
 	return triggerStrings[t]
 }
 
+// IsMemberTrigger returns true if the trigger type is ran from member events, in the context channel of the command
+func (t CommandTriggerType) IsMemberTrigger() bool {
+	return t == CommandTriggerMemberJoin || t == CommandTriggerMemberLeave || t == CommandTriggerRoleAdded || t == CommandTriggerRoleRemoved
+}
+
+// ReactionMode decides if reaction triggered commands runs on added reactions, removed reactions or both
+type ReactionMode int
+
+const (
+	ReactionModeBoth       ReactionMode = 0
+	ReactionModeAddOnly    ReactionMode = 1
+	ReactionModeRemoveOnly ReactionMode = 2
+)
+
+func (r ReactionMode) Matches(added bool) bool {
+	switch r {
+	case ReactionModeAddOnly:
+		return added
+	case ReactionModeRemoveOnly:
+		return !added
+	}
+
+	return true
+}
+
 type CustomCommand struct {
 	TriggerType     CommandTriggerType `json:"trigger_type"`
 	TriggerTypeForm string             `json:"-" schema:"type"`
@@ -99,6 +144,10 @@ type CustomCommand struct {
 	TimeTriggerExcludingDays  []int64 `schema:"time_trigger_excluding_days"`
 	TimeTriggerExcludingHours []int64 `schema:"time_trigger_excluding_hours"`
 
+	// Cron schedule triggers, ran in the IANA timezone (UTC if empty)
+	TimeTriggerCron     string `schema:"time_trigger_cron" valid:",0,100"`
+	TimeTriggerTimezone string `schema:"time_trigger_timezone" valid:",0,100"`
+
 	// If set, then the following channels are required, otherwise they are ignored
 	RequireChannels bool    `json:"require_channels" schema:"require_channels"`
 	Channels        []int64 `json:"channels" schema:"channels"`
@@ -107,6 +156,13 @@ type CustomCommand struct {
 	RequireRoles bool    `json:"require_roles" schema:"require_roles"`
 	Roles        []int64 `json:"roles" schema:"roles"`
 
+	// Reaction triggers, the emoji are in Trigger, and the message ID's are space or comma separated
+	ReactionTriggerMode     int    `json:"reaction_trigger_mode" schema:"reaction_trigger_mode"`
+	ReactionTriggerMessages string `json:"-" schema:"reaction_trigger_messages" valid:",0,1000"`
+
+	// Role added/removed triggers, if empty any role triggers it
+	TriggerRoles []int64 `json:"trigger_roles" schema:"trigger_roles" valid:"role,true"`
+
 	GroupID int64
 }
 
@@ -140,6 +196,25 @@ func (cc *CustomCommand) Validate(tmpl web.TemplateData) (ok bool) {
 		return false
 	}
 
+	if cc.TriggerTypeForm == "interval_cron" {
+		schedule, err := ParseCronSchedule(cc.TimeTriggerCron)
+		if err != nil {
+			tmpl.AddAlerts(web.ErrorAlert("Invalid cron schedule: " + err.Error()))
+			return false
+		}
+
+		loc, err := LoadCronLocation(strings.TrimSpace(cc.TimeTriggerTimezone))
+		if err != nil {
+			tmpl.AddAlerts(web.ErrorAlert(err.Error()))
+			return false
+		}
+
+		if schedule.Next(time.Now().In(loc)).IsZero() {
+			tmpl.AddAlerts(web.ErrorAlert("That cron schedule never runs"))
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -178,9 +253,56 @@ func (cc *CustomCommand) ToDBModel() *models.CustomCommand {
 		pqCommand.TimeTriggerInterval *= 60
 	}
 
+	if cc.TriggerTypeForm == "interval_cron" {
+		pqCommand.TimeTriggerCron = strings.TrimSpace(cc.TimeTriggerCron)
+		pqCommand.TimeTriggerTimezone = strings.TrimSpace(cc.TimeTriggerTimezone)
+		pqCommand.TimeTriggerExcludingDays = []int64{}
+		pqCommand.TimeTriggerExcludingHours = []int64{}
+
+		// the schedule decides when it runs, the interval is only kept for the low interval limits
+		pqCommand.TimeTriggerInterval = 0
+		schedule, err := ParseCronSchedule(pqCommand.TimeTriggerCron)
+		loc, errLoc := LoadCronLocation(pqCommand.TimeTriggerTimezone)
+		if err == nil && errLoc == nil {
+			pqCommand.TimeTriggerInterval = int(schedule.MinInterval(time.Now().In(loc), 50) / time.Minute)
+		}
+	}
+
+	if cc.TriggerTypeForm == "reaction" {
+		pqCommand.ReactionTriggerMode = cc.ReactionTriggerMode
+		pqCommand.ReactionTriggerMessages = parseIDList(cc.ReactionTriggerMessages)
+	}
+
+	if TriggerTypeFromForm(cc.TriggerTypeForm).IsMemberTrigger() {
+		// there's no text trigger for these, the field is just hidden in the form
+		pqCommand.TextTrigger = ""
+		pqCommand.TextTriggerCaseSensitive = false
+	}
+
+	if cc.TriggerTypeForm == "role_added" || cc.TriggerTypeForm == "role_removed" {
+		pqCommand.TriggerRoles = cc.TriggerRoles
+	}
+
 	return pqCommand
 }
 
+// parseIDList parses a space or comma separated list of ID's, ignoring invalid ones
+func parseIDList(str string) []int64 {
+	fields := strings.FieldsFunc(str, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	result := make([]int64, 0, len(fields))
+	for _, v := range fields {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && parsed > 0 && !common.ContainsInt64Slice(result, parsed) {
+			result = append(result, parsed)
+		}
+	}
+
+	return result
+}
+
 func CmdRunsInChannel(cc *models.CustomCommand, channel int64) bool {
 	if cc.GroupID.Valid {
 		// check group restrictions
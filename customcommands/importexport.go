@@ -0,0 +1,375 @@
+package customcommands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/customcommands/models"
+	"github.com/jonas747/yagpdb/web"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CommandsExportVersion is the current version of the export format, bump it on breaking changes
+const CommandsExportVersion = 1
+
+// CommandsExport is a bundle of custom commands and the groups they're in, in a format that can be imported on other servers
+type CommandsExport struct {
+	Version int `json:"version"`
+
+	Groups   []*ExportedGroup   `json:"groups"`
+	Commands []*ExportedCommand `json:"commands"`
+
+	// The names of the roles and channels referenced, used to help remapping them on import
+	web.ExportedRefs
+}
+
+type ExportedGroup struct {
+	// The ID the group had on the exporting server, commands reference groups by this
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+
+	WhitelistChannels []int64 `json:"whitelist_channels"`
+	IgnoreChannels    []int64 `json:"ignore_channels"`
+	WhitelistRoles    []int64 `json:"whitelist_roles"`
+	IgnoreRoles       []int64 `json:"ignore_roles"`
+}
+
+type ExportedCommand struct {
+	// The ID the command had on the exporting server, a new one is generated on import
+	LocalID int64 `json:"local_id"`
+	// 0 if ungrouped
+	GroupID int64 `json:"group_id"`
+
+	*CommandSettings
+}
+
+// ExportCommands creates a export of the commands and groups, groups referenced by the commands that's not in groups are left out
+// and the commands become ungrouped on import.
+func ExportCommands(g *discordgo.Guild, cmds []*models.CustomCommand, groups []*models.CustomCommandGroup) *CommandsExport {
+	export := &CommandsExport{
+		Version: CommandsExportVersion,
+	}
+
+	var roleIDs []int64
+	var channelIDs []int64
+	addRefs := func(dst *[]int64, ids []int64) {
+		for _, id := range ids {
+			if id != 0 && !common.ContainsInt64Slice(*dst, id) {
+				*dst = append(*dst, id)
+			}
+		}
+	}
+
+	groupIDs := make([]int64, 0, len(groups))
+	for _, v := range groups {
+		groupIDs = append(groupIDs, v.ID)
+		export.Groups = append(export.Groups, &ExportedGroup{
+			ID:                v.ID,
+			Name:              v.Name,
+			WhitelistChannels: v.WhitelistChannels,
+			IgnoreChannels:    v.IgnoreChannels,
+			WhitelistRoles:    v.WhitelistRoles,
+			IgnoreRoles:       v.IgnoreRoles,
+		})
+
+		addRefs(&channelIDs, v.WhitelistChannels)
+		addRefs(&channelIDs, v.IgnoreChannels)
+		addRefs(&roleIDs, v.WhitelistRoles)
+		addRefs(&roleIDs, v.IgnoreRoles)
+	}
+
+	for _, v := range cmds {
+		exported := &ExportedCommand{
+			LocalID:         v.LocalID,
+			CommandSettings: CommandSettingsFromModel(v),
+		}
+
+		if v.GroupID.Valid && common.ContainsInt64Slice(groupIDs, v.GroupID.Int64) {
+			exported.GroupID = v.GroupID.Int64
+		}
+
+		export.Commands = append(export.Commands, exported)
+
+		addRefs(&channelIDs, v.Channels)
+		addRefs(&channelIDs, []int64{v.ContextChannel})
+		addRefs(&roleIDs, v.Roles)
+		addRefs(&roleIDs, v.TriggerRoles)
+	}
+
+	export.ExportedRefs = web.NewExportedRefs(g, roleIDs, channelIDs)
+	return export
+}
+
+// ParseCommandsExport parses and validates a custom commands export
+func ParseCommandsExport(data []byte) (*CommandsExport, error) {
+	var export CommandsExport
+	err := json.Unmarshal(data, &export)
+	if err != nil {
+		return nil, web.NewPublicError("Failed parsing the custom commands export, is it valid JSON?")
+	}
+
+	if export.Version < 1 || export.Version > CommandsExportVersion {
+		return nil, web.NewPublicError(fmt.Sprintf("Unsupported custom commands export version: %d", export.Version))
+	}
+
+	if len(export.Commands) < 1 && len(export.Groups) < 1 {
+		return nil, web.NewPublicError("The export contains no custom commands or groups")
+	}
+
+	if len(export.Groups) > MaxGroups {
+		return nil, web.NewPublicError(fmt.Sprintf("The export contains more than %d groups", MaxGroups))
+	}
+
+	for _, v := range export.Groups {
+		if strings.TrimSpace(v.Name) == "" || utf8.RuneCountInString(v.Name) > 100 {
+			return nil, web.NewPublicError("The export contains a group with a empty or too long name (max 100 characters)")
+		}
+	}
+
+	for _, v := range export.Commands {
+		if v.CommandSettings == nil {
+			return nil, web.NewPublicError(fmt.Sprintf("Custom command #%d in the export has no settings", v.LocalID))
+		}
+
+		if err := v.validate(); err != nil {
+			return nil, web.NewPublicError(fmt.Sprintf("Custom command #%d in the export is invalid: %s", v.LocalID, err.Error()))
+		}
+	}
+
+	return &export, nil
+}
+
+// validate applies the same checks as the web form
+func (c *ExportedCommand) validate() error {
+	if !isKnownTriggerType(CommandTriggerType(c.TriggerType)) {
+		return errors.Errorf("unknown trigger type %d", c.TriggerType)
+	}
+
+	if utf8.RuneCountInString(c.TextTrigger) > 1000 {
+		return errors.New("trigger is too long (max 1000)")
+	}
+
+	if len(c.Responses) > MaxUserMessages {
+		return errors.Errorf("too many responses, max %d", MaxUserMessages)
+	}
+
+	foundOkayResponse := false
+	combinedSize := 0
+	for _, v := range c.Responses {
+		if strings.TrimSpace(v) != "" {
+			foundOkayResponse = true
+		}
+		combinedSize += len(v)
+
+		if err := web.ValidateTemplateField(v, 10000); err != nil {
+			return errors.WithMessage(err, "invalid response")
+		}
+	}
+
+	if !foundOkayResponse {
+		return errors.New("no response set")
+	}
+
+	if combinedSize > 10000 {
+		return errors.New("max combined command size can be 10k")
+	}
+
+	if CommandTriggerType(c.TriggerType) == CommandTriggerInterval {
+		if c.TimeTriggerCron != "" {
+			schedule, err := ParseCronSchedule(c.TimeTriggerCron)
+			if err != nil {
+				return errors.WithMessage(err, "invalid cron schedule")
+			}
+
+			loc, err := LoadCronLocation(c.TimeTriggerTimezone)
+			if err != nil {
+				return err
+			}
+
+			next := time.Now().In(loc)
+			if schedule.Next(next).IsZero() {
+				return errors.New("cron schedule never runs")
+			}
+
+			// don't trust the interval in the export, it's used for the low interval limits
+			c.TimeTriggerInterval = int(schedule.MinInterval(next, 50) / time.Minute)
+		} else if c.TimeTriggerInterval < 1 {
+			return errors.New("interval has to be at least 1 minute")
+		}
+	}
+
+	return nil
+}
+
+func isKnownTriggerType(t CommandTriggerType) bool {
+	for _, v := range AllTriggerTypes {
+		if v == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ImportCommands creates the groups and commands in the export, remapping the roles and channels that don't exist on the guild using remap.
+// Groups with the same name as a existing group are merged into that group.
+// If the import would exceed any of the limits an alert is added to tmpl and ok is set to false.
+// Everything is created in a single transaction so either everything gets created or nothing.
+func ImportCommands(ctx context.Context, g *discordgo.Guild, tmpl web.TemplateData, export *CommandsExport, remap *web.ImportRemap) (cmds []*models.CustomCommand, ok bool, err error) {
+	tx, err := common.PQ.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cmds, ok, err = importCommandsTx(ctx, tx, g, tmpl, export, remap)
+	if err != nil || !ok {
+		tx.Rollback()
+		return nil, ok, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return cmds, true, nil
+}
+
+func importCommandsTx(ctx context.Context, tx *sql.Tx, g *discordgo.Guild, tmpl web.TemplateData, export *CommandsExport, remap *web.ImportRemap) ([]*models.CustomCommand, bool, error) {
+	// lock the tables so that imports running at the same time can't get past the limits together
+	_, err := tx.Exec("LOCK TABLE custom_commands, custom_command_groups IN EXCLUSIVE MODE")
+	if err != nil {
+		return nil, false, errors.WithMessage(err, "lock")
+	}
+
+	ok, err := checkImportLimits(ctx, tx, g, tmpl, export)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	groupMap, ok, err := importGroups(ctx, tx, g, tmpl, export.Groups, remap)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	result := make([]*models.CustomCommand, 0, len(export.Commands))
+	for _, v := range export.Commands {
+		localID, err := common.GenLocalIncrID(g.ID, "custom_command")
+		if err != nil {
+			return nil, false, errors.WithMessage(err, "error generating local id")
+		}
+
+		cc := &models.CustomCommand{
+			GuildID: g.ID,
+			LocalID: localID,
+		}
+		v.ApplyTo(cc)
+
+		if groupID, ok := groupMap[v.GroupID]; ok {
+			cc.GroupID = null.Int64From(groupID)
+		}
+
+		cc.Channels = remap.Channels(cc.Channels)
+		cc.Roles = remap.Roles(cc.Roles)
+		cc.TriggerRoles = remap.Roles(cc.TriggerRoles)
+		cc.ContextChannel = remap.Channel(cc.ContextChannel)
+
+		err = cc.Insert(ctx, tx, boil.Infer())
+		if err != nil {
+			return nil, false, errors.WithMessage(err, "insert command")
+		}
+
+		result = append(result, cc)
+	}
+
+	return result, true, nil
+}
+
+// checkImportLimits checks that importing the commands wouldn't exceed the max number of commands or low interval commands
+func checkImportLimits(ctx context.Context, tx *sql.Tx, g *discordgo.Guild, tmpl web.TemplateData, export *CommandsExport) (bool, error) {
+	numCommands, err := models.CustomCommands(qm.Where("guild_id = ?", g.ID)).Count(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if int(numCommands)+len(export.Commands) > MaxCommandsForContext(ctx) {
+		tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Importing these custom commands would exceed the max number of custom commands, %d for normal servers and %d for premium servers", MaxCommands, MaxCommandsPremium)))
+		return false, nil
+	}
+
+	numLowInterval := 0
+	for _, v := range export.Commands {
+		if CommandTriggerType(v.TriggerType) == CommandTriggerInterval && v.TimeTriggerInterval < 10 {
+			numLowInterval++
+		}
+	}
+
+	if numLowInterval > 0 {
+		num, err := models.CustomCommands(qm.Where("guild_id = ? AND trigger_type = 5 AND time_trigger_interval < 10", g.ID)).Count(ctx, tx)
+		if err != nil {
+			return false, err
+		}
+
+		if int(num)+numLowInterval > 5 {
+			tmpl.AddAlerts(web.ErrorAlert("You can have max 5 triggers on less than 10 minute intervals"))
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// importGroups creates the groups in the export, reusing existing groups with the same name, returning a map of old id -> new id
+func importGroups(ctx context.Context, tx *sql.Tx, g *discordgo.Guild, tmpl web.TemplateData, groups []*ExportedGroup, remap *web.ImportRemap) (map[int64]int64, bool, error) {
+	result := make(map[int64]int64)
+	if len(groups) < 1 {
+		return result, true, nil
+	}
+
+	existing, err := models.CustomCommandGroups(qm.Where("guild_id = ?", g.ID)).All(ctx, tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+OUTER:
+	for _, v := range groups {
+		for _, e := range existing {
+			if strings.EqualFold(e.Name, v.Name) {
+				result[v.ID] = e.ID
+				continue OUTER
+			}
+		}
+
+		if len(existing) >= MaxGroups {
+			tmpl.AddAlerts(web.ErrorAlert(fmt.Sprintf("Importing these groups would exceed the max number of custom command groups (%d)", MaxGroups)))
+			return nil, false, nil
+		}
+
+		group := &models.CustomCommandGroup{
+			GuildID:           g.ID,
+			Name:              strings.TrimSpace(v.Name),
+			WhitelistChannels: remap.Channels(v.WhitelistChannels),
+			IgnoreChannels:    remap.Channels(v.IgnoreChannels),
+			WhitelistRoles:    remap.Roles(v.WhitelistRoles),
+			IgnoreRoles:       remap.Roles(v.IgnoreRoles),
+		}
+
+		err = group.Insert(ctx, tx, boil.Infer())
+		if err != nil {
+			return nil, false, errors.WithMessage(err, "insert group")
+		}
+
+		existing = append(existing, group)
+		result[v.ID] = group.ID
+	}
+
+	return result, true, nil
+}
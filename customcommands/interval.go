@@ -14,6 +14,10 @@ import (
 )
 
 func CalcNextRunTime(cc *models.CustomCommand, now time.Time) time.Time {
+	if cc.TimeTriggerCron != "" {
+		return calcNextCronRunTime(cc, now)
+	}
+
 	if len(cc.TimeTriggerExcludingDays) >= 7 || len(cc.TimeTriggerExcludingHours) >= 24 {
 		// this can never be ran...
 		return time.Time{}
@@ -61,6 +65,33 @@ func CalcNextRunTime(cc *models.CustomCommand, now time.Time) time.Time {
 	return tNext
 }
 
+// calcNextCronRunTime returns the next time the cron schedule of the command runs after now, evaluated in the timezone
+// of the command so that it follows daylight saving changes
+func calcNextCronRunTime(cc *models.CustomCommand, now time.Time) time.Time {
+	schedule, err := ParseCronSchedule(cc.TimeTriggerCron)
+	if err != nil {
+		return time.Time{}
+	}
+
+	loc, err := LoadCronLocation(cc.TimeTriggerTimezone)
+	if err != nil {
+		return time.Time{}
+	}
+
+	// scheduled events can fire slightly early, make sure it's not ran twice for the same time
+	after := now
+	if cc.LastRun.Valid && cc.LastRun.Time.After(after) {
+		after = cc.LastRun.Time
+	}
+
+	next := schedule.Next(after.In(loc))
+	if next.IsZero() {
+		return next
+	}
+
+	return next.UTC()
+}
+
 type NextRunScheduledEvent struct {
 	CmdID int64 `json:"cmd_id"`
 }
@@ -78,7 +109,7 @@ func UpdateCommandNextRunTime(cc *models.CustomCommand, updateLastRun bool) erro
 		return errors.Wrap(err, "del_old_events")
 	}
 
-	if cc.TriggerType != int(CommandTriggerInterval) || cc.TimeTriggerInterval < 1 {
+	if cc.TriggerType != int(CommandTriggerInterval) || (cc.TimeTriggerInterval < 1 && cc.TimeTriggerCron == "") {
 		return nil
 	}
 
@@ -0,0 +1,152 @@
+package customcommands
+
+import (
+	"context"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/dstate"
+	"github.com/jonas747/yagpdb/bot"
+	"github.com/jonas747/yagpdb/bot/eventsystem"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/common/templates"
+	"github.com/jonas747/yagpdb/customcommands/models"
+	log "github.com/sirupsen/logrus"
+)
+
+func HandleGuildMemberAdd(evt *eventsystem.EventData) {
+	ma := evt.GuildMemberAdd()
+
+	gs := bot.State.Guild(true, ma.GuildID)
+	if gs == nil {
+		return
+	}
+
+	ms := dstate.MSFromDGoMember(gs, ma.Member)
+	runMemberTriggers(evt.Context(), gs, ms, CommandTriggerMemberJoin, nil)
+}
+
+// HandleGuildMemberRemove runs before the state is updated, so that the roles of the member is still available
+func HandleGuildMemberRemove(evt *eventsystem.EventData) {
+	mr := evt.GuildMemberRemove()
+
+	gs := bot.State.Guild(true, mr.GuildID)
+	if gs == nil {
+		return
+	}
+
+	ms := gs.MemberCopy(true, mr.User.ID)
+	if ms == nil || !ms.MemberSet {
+		ms = dstate.MSFromDGoMember(gs, mr.Member)
+	}
+
+	go runMemberTriggers(evt.Context(), gs, ms, CommandTriggerMemberLeave, nil)
+}
+
+// HandleGuildMemberUpdate runs before the state is updated, so we can compare the old roles of the member against the new ones
+func HandleGuildMemberUpdate(evt *eventsystem.EventData) {
+	mu := evt.GuildMemberUpdate()
+
+	gs := bot.State.Guild(true, mu.GuildID)
+	if gs == nil {
+		return
+	}
+
+	old := gs.MemberCopy(true, mu.User.ID)
+	if old == nil || !old.MemberSet {
+		// we don't know what roles they had before
+		return
+	}
+
+	added := rolesNotIn(mu.Roles, old.Roles)
+	removed := rolesNotIn(old.Roles, mu.Roles)
+	if len(added) < 1 && len(removed) < 1 {
+		return
+	}
+
+	go func() {
+		ms := dstate.MSFromDGoMember(gs, mu.Member)
+		for _, r := range added {
+			runMemberTriggers(evt.Context(), gs, ms, CommandTriggerRoleAdded, gs.RoleCopy(true, r))
+		}
+
+		for _, r := range removed {
+			runMemberTriggers(evt.Context(), gs, ms, CommandTriggerRoleRemoved, gs.RoleCopy(true, r))
+		}
+	}()
+}
+
+// rolesNotIn returns the roles in a that's not in b
+func rolesNotIn(a, b []int64) []int64 {
+	var result []int64
+	for _, r := range a {
+		if !common.ContainsInt64Slice(b, r) {
+			result = append(result, r)
+		}
+	}
+
+	return result
+}
+
+// runMemberTriggers runs all the commands with the trigger type that applies to the member, unlike message triggers
+// all matching commands are ran
+func runMemberTriggers(ctx context.Context, gs *dstate.GuildState, ms *dstate.MemberState, triggerType CommandTriggerType, role *discordgo.Role) {
+	if ms.ID == common.BotUser.ID {
+		return
+	}
+
+	if (triggerType == CommandTriggerRoleAdded || triggerType == CommandTriggerRoleRemoved) && role == nil {
+		// role was deleted
+		return
+	}
+
+	cmds, err := BotCachedGetCommandsWithMemberTriggers(gs, ctx)
+	if err != nil {
+		log.WithError(err).WithField("guild", gs.ID).Error("Failed retrieving comamnds")
+		return
+	}
+
+	for _, cmd := range cmds {
+		if !CheckMemberTriggerMatch(cmd, triggerType, role) || len(cmd.Responses) == 0 {
+			continue
+		}
+
+		if !CmdRunsInChannel(cmd, cmd.ContextChannel) || !CmdRunsForUser(cmd, ms) {
+			continue
+		}
+
+		cs := gs.Channel(true, cmd.ContextChannel)
+		if cs == nil {
+			continue
+		}
+
+		if !bot.BotProbablyHasPermissionGS(true, gs, cs.ID, discordgo.PermissionSendMessages) {
+			continue
+		}
+
+		if common.Statsd != nil {
+			go common.Statsd.Incr("yagpdb.cc.executed", nil, 1)
+		}
+
+		tmplCtx := templates.NewContext(gs, cs, ms)
+		if role != nil {
+			tmplCtx.Data["Role"] = role
+		}
+
+		err = ExecuteCustomCommand(cmd, tmplCtx)
+		if err != nil {
+			log.WithField("guild", gs.ID).WithError(err).Error("Error executing custom command")
+		}
+	}
+}
+
+// CheckMemberTriggerMatch returns true if the member triggered command should run for the trigger type and role (nil for join and leave)
+func CheckMemberTriggerMatch(cmd *models.CustomCommand, triggerType CommandTriggerType, role *discordgo.Role) bool {
+	if CommandTriggerType(cmd.TriggerType) != triggerType {
+		return false
+	}
+
+	if role == nil || len(cmd.TriggerRoles) < 1 {
+		return true
+	}
+
+	return common.ContainsInt64Slice(cmd.TriggerRoles, role.ID)
+}
@@ -13,60 +13,70 @@ import "testing"
 // Separating the tests thusly grants avoidance of Postgres deadlocks.
 func TestParent(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroups)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisions)
 	t.Run("CustomCommands", testCustomCommands)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabases)
 }
 
 func TestDelete(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsDelete)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsDelete)
 	t.Run("CustomCommands", testCustomCommandsDelete)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesDelete)
 }
 
 func TestQueryDeleteAll(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsQueryDeleteAll)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsQueryDeleteAll)
 	t.Run("CustomCommands", testCustomCommandsQueryDeleteAll)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesQueryDeleteAll)
 }
 
 func TestSliceDeleteAll(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsSliceDeleteAll)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsSliceDeleteAll)
 	t.Run("CustomCommands", testCustomCommandsSliceDeleteAll)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesSliceDeleteAll)
 }
 
 func TestExists(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsExists)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsExists)
 	t.Run("CustomCommands", testCustomCommandsExists)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesExists)
 }
 
 func TestFind(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsFind)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsFind)
 	t.Run("CustomCommands", testCustomCommandsFind)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesFind)
 }
 
 func TestBind(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsBind)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsBind)
 	t.Run("CustomCommands", testCustomCommandsBind)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesBind)
 }
 
 func TestOne(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsOne)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsOne)
 	t.Run("CustomCommands", testCustomCommandsOne)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesOne)
 }
 
 func TestAll(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsAll)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsAll)
 	t.Run("CustomCommands", testCustomCommandsAll)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesAll)
 }
 
 func TestCount(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsCount)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsCount)
 	t.Run("CustomCommands", testCustomCommandsCount)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesCount)
 }
@@ -74,6 +84,8 @@ func TestCount(t *testing.T) {
 func TestInsert(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsInsert)
 	t.Run("CustomCommandGroups", testCustomCommandGroupsInsertWhitelist)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsInsert)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsInsertWhitelist)
 	t.Run("CustomCommands", testCustomCommandsInsert)
 	t.Run("CustomCommands", testCustomCommandsInsertWhitelist)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesInsert)
@@ -136,30 +148,35 @@ func TestToManyRemove(t *testing.T) {
 
 func TestReload(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsReload)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsReload)
 	t.Run("CustomCommands", testCustomCommandsReload)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesReload)
 }
 
 func TestReloadAll(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsReloadAll)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsReloadAll)
 	t.Run("CustomCommands", testCustomCommandsReloadAll)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesReloadAll)
 }
 
 func TestSelect(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsSelect)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsSelect)
 	t.Run("CustomCommands", testCustomCommandsSelect)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesSelect)
 }
 
 func TestUpdate(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsUpdate)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsUpdate)
 	t.Run("CustomCommands", testCustomCommandsUpdate)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesUpdate)
 }
 
 func TestSliceUpdateAll(t *testing.T) {
 	t.Run("CustomCommandGroups", testCustomCommandGroupsSliceUpdateAll)
+	t.Run("CustomCommandRevisions", testCustomCommandRevisionsSliceUpdateAll)
 	t.Run("CustomCommands", testCustomCommandsSliceUpdateAll)
 	t.Run("TemplatesUserDatabases", testTemplatesUserDatabasesSliceUpdateAll)
 }
@@ -42,6 +42,11 @@ type CustomCommand struct {
 	Roles                     types.Int64Array  `boil:"roles" json:"roles,omitempty" toml:"roles" yaml:"roles,omitempty"`
 	RolesWhitelistMode        bool              `boil:"roles_whitelist_mode" json:"roles_whitelist_mode" toml:"roles_whitelist_mode" yaml:"roles_whitelist_mode"`
 	ContextChannel            int64             `boil:"context_channel" json:"context_channel" toml:"context_channel" yaml:"context_channel"`
+	ReactionTriggerMode       int               `boil:"reaction_trigger_mode" json:"reaction_trigger_mode" toml:"reaction_trigger_mode" yaml:"reaction_trigger_mode"`
+	ReactionTriggerMessages   types.Int64Array  `boil:"reaction_trigger_messages" json:"reaction_trigger_messages,omitempty" toml:"reaction_trigger_messages" yaml:"reaction_trigger_messages,omitempty"`
+	TriggerRoles              types.Int64Array  `boil:"trigger_roles" json:"trigger_roles,omitempty" toml:"trigger_roles" yaml:"trigger_roles,omitempty"`
+	TimeTriggerCron           string            `boil:"time_trigger_cron" json:"time_trigger_cron" toml:"time_trigger_cron" yaml:"time_trigger_cron"`
+	TimeTriggerTimezone       string            `boil:"time_trigger_timezone" json:"time_trigger_timezone" toml:"time_trigger_timezone" yaml:"time_trigger_timezone"`
 
 	R *customCommandR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L customCommandL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -65,6 +70,11 @@ var CustomCommandColumns = struct {
 	Roles                     string
 	RolesWhitelistMode        string
 	ContextChannel            string
+	ReactionTriggerMode       string
+	ReactionTriggerMessages   string
+	TriggerRoles              string
+	TimeTriggerCron           string
+	TimeTriggerTimezone       string
 }{
 	LocalID:                   "local_id",
 	GuildID:                   "guild_id",
@@ -83,6 +93,11 @@ var CustomCommandColumns = struct {
 	Roles:                     "roles",
 	RolesWhitelistMode:        "roles_whitelist_mode",
 	ContextChannel:            "context_channel",
+	ReactionTriggerMode:       "reaction_trigger_mode",
+	ReactionTriggerMessages:   "reaction_trigger_messages",
+	TriggerRoles:              "trigger_roles",
+	TimeTriggerCron:           "time_trigger_cron",
+	TimeTriggerTimezone:       "time_trigger_timezone",
 }
 
 // Generated where
@@ -190,6 +205,11 @@ var CustomCommandWhere = struct {
 	Roles                     whereHelpertypes_Int64Array
 	RolesWhitelistMode        whereHelperbool
 	ContextChannel            whereHelperint64
+	ReactionTriggerMode       whereHelperint
+	ReactionTriggerMessages   whereHelpertypes_Int64Array
+	TriggerRoles              whereHelpertypes_Int64Array
+	TimeTriggerCron           whereHelperstring
+	TimeTriggerTimezone       whereHelperstring
 }{
 	LocalID:                   whereHelperint64{field: `local_id`},
 	GuildID:                   whereHelperint64{field: `guild_id`},
@@ -208,6 +228,11 @@ var CustomCommandWhere = struct {
 	Roles:                     whereHelpertypes_Int64Array{field: `roles`},
 	RolesWhitelistMode:        whereHelperbool{field: `roles_whitelist_mode`},
 	ContextChannel:            whereHelperint64{field: `context_channel`},
+	ReactionTriggerMode:       whereHelperint{field: `reaction_trigger_mode`},
+	ReactionTriggerMessages:   whereHelpertypes_Int64Array{field: `reaction_trigger_messages`},
+	TriggerRoles:              whereHelpertypes_Int64Array{field: `trigger_roles`},
+	TimeTriggerCron:           whereHelperstring{field: `time_trigger_cron`},
+	TimeTriggerTimezone:       whereHelperstring{field: `time_trigger_timezone`},
 }
 
 // CustomCommandRels is where relationship names are stored.
@@ -231,9 +256,9 @@ func (*customCommandR) NewStruct() *customCommandR {
 type customCommandL struct{}
 
 var (
-	customCommandColumns               = []string{"local_id", "guild_id", "group_id", "trigger_type", "text_trigger", "text_trigger_case_sensitive", "time_trigger_interval", "time_trigger_excluding_days", "time_trigger_excluding_hours", "last_run", "next_run", "responses", "channels", "channels_whitelist_mode", "roles", "roles_whitelist_mode", "context_channel"}
-	customCommandColumnsWithoutDefault = []string{"local_id", "guild_id", "group_id", "trigger_type", "text_trigger", "text_trigger_case_sensitive", "time_trigger_interval", "time_trigger_excluding_days", "time_trigger_excluding_hours", "last_run", "next_run", "responses", "channels", "channels_whitelist_mode", "roles", "roles_whitelist_mode"}
-	customCommandColumnsWithDefault    = []string{"context_channel"}
+	customCommandColumns               = []string{"local_id", "guild_id", "group_id", "trigger_type", "text_trigger", "text_trigger_case_sensitive", "time_trigger_interval", "time_trigger_excluding_days", "time_trigger_excluding_hours", "last_run", "next_run", "responses", "channels", "channels_whitelist_mode", "roles", "roles_whitelist_mode", "context_channel", "reaction_trigger_mode", "reaction_trigger_messages", "trigger_roles", "time_trigger_cron", "time_trigger_timezone"}
+	customCommandColumnsWithoutDefault = []string{"local_id", "guild_id", "group_id", "trigger_type", "text_trigger", "text_trigger_case_sensitive", "time_trigger_interval", "time_trigger_excluding_days", "time_trigger_excluding_hours", "last_run", "next_run", "responses", "channels", "channels_whitelist_mode", "roles", "roles_whitelist_mode", "reaction_trigger_messages", "trigger_roles"}
+	customCommandColumnsWithDefault    = []string{"context_channel", "reaction_trigger_mode", "time_trigger_cron", "time_trigger_timezone"}
 	customCommandPrimaryKeyColumns     = []string{"guild_id", "local_id"}
 )
 
@@ -0,0 +1,330 @@
+package customcommands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/customcommands/models"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"strings"
+)
+
+// Max number of revisions kept per command, older ones are removed when new ones are saved
+const MaxCommandRevisions = 100
+
+// CommandSettings is a snapshot of the responses, trigger and settings of a custom command
+type CommandSettings struct {
+	TriggerType              int    `json:"trigger_type"`
+	TextTrigger              string `json:"text_trigger"`
+	TextTriggerCaseSensitive bool   `json:"text_trigger_case_sensitive"`
+
+	Responses []string `json:"responses"`
+
+	Channels              []int64 `json:"channels"`
+	ChannelsWhitelistMode bool    `json:"channels_whitelist_mode"`
+	Roles                 []int64 `json:"roles"`
+	RolesWhitelistMode    bool    `json:"roles_whitelist_mode"`
+
+	ContextChannel int64 `json:"context_channel"`
+
+	TimeTriggerInterval       int     `json:"time_trigger_interval"`
+	TimeTriggerExcludingDays  []int64 `json:"time_trigger_excluding_days"`
+	TimeTriggerExcludingHours []int64 `json:"time_trigger_excluding_hours"`
+	TimeTriggerCron           string  `json:"time_trigger_cron"`
+	TimeTriggerTimezone       string  `json:"time_trigger_timezone"`
+
+	ReactionTriggerMode     int     `json:"reaction_trigger_mode"`
+	ReactionTriggerMessages []int64 `json:"reaction_trigger_messages"`
+
+	TriggerRoles []int64 `json:"trigger_roles"`
+}
+
+func CommandSettingsFromModel(cc *models.CustomCommand) *CommandSettings {
+	return &CommandSettings{
+		TriggerType:              cc.TriggerType,
+		TextTrigger:              cc.TextTrigger,
+		TextTriggerCaseSensitive: cc.TextTriggerCaseSensitive,
+
+		Responses: cc.Responses,
+
+		Channels:              cc.Channels,
+		ChannelsWhitelistMode: cc.ChannelsWhitelistMode,
+		Roles:                 cc.Roles,
+		RolesWhitelistMode:    cc.RolesWhitelistMode,
+
+		ContextChannel: cc.ContextChannel,
+
+		TimeTriggerInterval:       cc.TimeTriggerInterval,
+		TimeTriggerExcludingDays:  cc.TimeTriggerExcludingDays,
+		TimeTriggerExcludingHours: cc.TimeTriggerExcludingHours,
+		TimeTriggerCron:           cc.TimeTriggerCron,
+		TimeTriggerTimezone:       cc.TimeTriggerTimezone,
+
+		ReactionTriggerMode:     cc.ReactionTriggerMode,
+		ReactionTriggerMessages: cc.ReactionTriggerMessages,
+
+		TriggerRoles: cc.TriggerRoles,
+	}
+}
+
+// ApplyTo sets the settings on the command, the guild, id, group and run times are left untouched
+func (s *CommandSettings) ApplyTo(cc *models.CustomCommand) {
+	cc.TriggerType = s.TriggerType
+	cc.TextTrigger = s.TextTrigger
+	cc.TextTriggerCaseSensitive = s.TextTriggerCaseSensitive
+
+	cc.Responses = s.Responses
+
+	cc.Channels = s.Channels
+	cc.ChannelsWhitelistMode = s.ChannelsWhitelistMode
+	cc.Roles = s.Roles
+	cc.RolesWhitelistMode = s.RolesWhitelistMode
+
+	cc.ContextChannel = s.ContextChannel
+
+	cc.TimeTriggerInterval = s.TimeTriggerInterval
+	cc.TimeTriggerExcludingDays = s.TimeTriggerExcludingDays
+	cc.TimeTriggerExcludingHours = s.TimeTriggerExcludingHours
+	cc.TimeTriggerCron = s.TimeTriggerCron
+	cc.TimeTriggerTimezone = s.TimeTriggerTimezone
+
+	cc.ReactionTriggerMode = s.ReactionTriggerMode
+	cc.ReactionTriggerMessages = s.ReactionTriggerMessages
+
+	cc.TriggerRoles = s.TriggerRoles
+
+	// these columns are not nullable
+	if cc.Responses == nil {
+		cc.Responses = []string{}
+	}
+	if cc.TimeTriggerExcludingDays == nil {
+		cc.TimeTriggerExcludingDays = []int64{}
+	}
+	if cc.TimeTriggerExcludingHours == nil {
+		cc.TimeTriggerExcludingHours = []int64{}
+	}
+}
+
+// Lines returns a human readable representation of the settings, used for showing the changes between revisions
+func (s *CommandSettings) Lines() []string {
+	lines := []string{
+		"Trigger type: " + CommandTriggerType(s.TriggerType).String(),
+		fmt.Sprintf("Trigger: %s (case sensitive: %t)", s.TextTrigger, s.TextTriggerCaseSensitive),
+		fmt.Sprintf("Channels: %v (whitelist: %t)", s.Channels, s.ChannelsWhitelistMode),
+		fmt.Sprintf("Roles: %v (whitelist: %t)", s.Roles, s.RolesWhitelistMode),
+		fmt.Sprintf("Context channel: %d", s.ContextChannel),
+	}
+
+	switch CommandTriggerType(s.TriggerType) {
+	case CommandTriggerInterval:
+		if s.TimeTriggerCron != "" {
+			lines = append(lines, fmt.Sprintf("Cron schedule: %s (timezone: %s)", s.TimeTriggerCron, s.TimeTriggerTimezone))
+		} else {
+			lines = append(lines, fmt.Sprintf("Interval: %d minute(s), excluding days %v and hours %v", s.TimeTriggerInterval, s.TimeTriggerExcludingDays, s.TimeTriggerExcludingHours))
+		}
+	case CommandTriggerReaction:
+		lines = append(lines, fmt.Sprintf("Reaction mode: %d, messages: %v", s.ReactionTriggerMode, s.ReactionTriggerMessages))
+	case CommandTriggerRoleAdded, CommandTriggerRoleRemoved:
+		lines = append(lines, fmt.Sprintf("Trigger roles: %v", s.TriggerRoles))
+	}
+
+	for i, v := range s.Responses {
+		lines = append(lines, "", fmt.Sprintf("Response #%d:", i+1))
+		lines = append(lines, strings.Split(v, "\n")...)
+	}
+
+	return lines
+}
+
+type DiffOp int
+
+const (
+	DiffOpEqual DiffOp = iota
+	DiffOpAdded
+	DiffOpRemoved
+)
+
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// Max size of the table used for the line diff (lines in a * lines in b, after removing the common start and end),
+// above this the changed lines are just shown as all removed and then all added
+const maxDiffCells = 1 << 20
+
+// DiffLines returns a line based diff going from a to b, based on the longest common subsequence
+func DiffLines(a, b []string) []DiffLine {
+	result := make([]DiffLine, 0, len(a)+len(b))
+
+	// the common start and end are usually most of it
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		result = append(result, DiffLine{Op: DiffOpEqual, Text: a[prefix]})
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result = diffLinesLCS(result, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+
+	for _, v := range a[len(a)-suffix:] {
+		result = append(result, DiffLine{Op: DiffOpEqual, Text: v})
+	}
+
+	return result
+}
+
+func diffLinesLCS(result []DiffLine, a, b []string) []DiffLine {
+	if len(a)*len(b) > maxDiffCells {
+		// too large to diff
+		for _, v := range a {
+			result = append(result, DiffLine{Op: DiffOpRemoved, Text: v})
+		}
+		for _, v := range b {
+			result = append(result, DiffLine{Op: DiffOpAdded, Text: v})
+		}
+		return result
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			result = append(result, DiffLine{Op: DiffOpEqual, Text: a[i]})
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			result = append(result, DiffLine{Op: DiffOpRemoved, Text: a[i]})
+			i++
+		} else {
+			result = append(result, DiffLine{Op: DiffOpAdded, Text: b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		result = append(result, DiffLine{Op: DiffOpRemoved, Text: a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		result = append(result, DiffLine{Op: DiffOpAdded, Text: b[j]})
+	}
+
+	return result
+}
+
+func linesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i, v := range a {
+		if b[i] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func revisionSettings(rev *models.CustomCommandRevision) (*CommandSettings, error) {
+	var settings CommandSettings
+	err := json.Unmarshal(rev.Data, &settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal_revision")
+	}
+
+	return &settings, nil
+}
+
+// SaveRevision stores the current state of the command as a new revision, unless nothing changed since the last one.
+// author is nil if it's not known
+func SaveRevision(ctx context.Context, cc *models.CustomCommand, author *discordgo.User) error {
+	settings := CommandSettingsFromModel(cc)
+
+	last, err := models.CustomCommandRevisions(qm.Where("guild_id = ? AND local_id = ?", cc.GuildID, cc.LocalID), qm.OrderBy("id desc")).OneG(ctx)
+	if err != nil && errors.Cause(err) != sql.ErrNoRows {
+		return errors.Wrap(err, "last_revision")
+	}
+
+	if last != nil {
+		lastSettings, err := revisionSettings(last)
+		if err == nil && linesEqual(lastSettings.Lines(), settings.Lines()) {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	rev := &models.CustomCommandRevision{
+		GuildID: cc.GuildID,
+		LocalID: cc.LocalID,
+		Data:    data,
+	}
+
+	if author != nil {
+		rev.AuthorID = author.ID
+		rev.AuthorUsername = author.Username + "#" + author.Discriminator
+	}
+
+	err = rev.InsertG(ctx, boil.Infer())
+	if err != nil {
+		return errors.Wrap(err, "insert_revision")
+	}
+
+	// remove the oldest ones above the limit
+	_, err = models.CustomCommandRevisions(
+		qm.Where("guild_id = ? AND local_id = ?", cc.GuildID, cc.LocalID),
+		qm.Where("id NOT IN (SELECT id FROM custom_command_revisions WHERE guild_id = ? AND local_id = ? ORDER BY id DESC LIMIT ?)", cc.GuildID, cc.LocalID, MaxCommandRevisions),
+	).DeleteAll(ctx, common.PQ)
+	return errors.Wrap(err, "prune_revisions")
+}
+
+// SaveInitialRevision stores the current state of the command if it has no revisions yet, so that the original of commands
+// created before revisions were stored isn't lost on the first change
+func SaveInitialRevision(ctx context.Context, guildID, localID int64) error {
+	exists, err := models.CustomCommandRevisions(qm.Where("guild_id = ? AND local_id = ?", guildID, localID)).ExistsG(ctx)
+	if err != nil || exists {
+		return errors.Wrap(err, "revision_exists")
+	}
+
+	cc, err := models.CustomCommands(qm.Where("guild_id = ? AND local_id = ?", guildID, localID)).OneG(ctx)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil
+		}
+
+		return errors.Wrap(err, "initial_revision_command")
+	}
+
+	return SaveRevision(ctx, cc, nil)
+}
@@ -0,0 +1,62 @@
+package customcommands
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := []string{"{{if .User}}", "hello", "{{end}}"}
+	b := []string{"{{if .User}}", "hello there", "{{end}}", "bye"}
+
+	want := []DiffLine{
+		{DiffOpEqual, "{{if .User}}"},
+		{DiffOpRemoved, "hello"},
+		{DiffOpAdded, "hello there"},
+		{DiffOpEqual, "{{end}}"},
+		{DiffOpAdded, "bye"},
+	}
+
+	got := DiffLines(a, b)
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines, want %d: %v", len(got), len(want), got)
+	}
+
+	for i, v := range got {
+		if v != want[i] {
+			t.Errorf("line %d: got %v, want %v", i, v, want[i])
+		}
+	}
+
+	for i, v := range DiffLines(nil, b) {
+		if v.Op != DiffOpAdded || v.Text != b[i] {
+			t.Errorf("diff against nothing: line %d: got %v", i, v)
+		}
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	a := make([]string, 0, 10002)
+	b := make([]string, 0, 10002)
+	a = append(a, "start")
+	b = append(b, "start")
+	for i := 0; i < 10000; i++ {
+		a = append(a, "a"+strconv.Itoa(i))
+		b = append(b, "b"+strconv.Itoa(i))
+	}
+	a = append(a, "end")
+	b = append(b, "end")
+
+	got := DiffLines(a, b)
+	if len(got) != 20002 {
+		t.Fatalf("got %d lines, want 20002", len(got))
+	}
+
+	if got[0].Op != DiffOpEqual || got[len(got)-1].Op != DiffOpEqual {
+		t.Errorf("common start and end should be kept as equal: %v, %v", got[0], got[len(got)-1])
+	}
+
+	if got[1].Op != DiffOpRemoved || got[10001].Op != DiffOpAdded {
+		t.Errorf("too large to diff, expected everything in between to be removed and then added: %v, %v", got[1], got[10001])
+	}
+}
@@ -45,6 +45,25 @@ CREATE INDEX IF NOT EXISTS custom_commands_guild_idx ON custom_commands(guild_id
 CREATE INDEX IF NOT EXISTS custom_commands_next_run_idx ON custom_commands(next_run);
 
 ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS context_channel BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS reaction_trigger_mode INT NOT NULL DEFAULT 0;
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS reaction_trigger_messages BIGINT[];
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS trigger_roles BIGINT[];
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS time_trigger_cron TEXT NOT NULL DEFAULT '';
+ALTER TABLE custom_commands ADD COLUMN IF NOT EXISTS time_trigger_timezone TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS custom_command_revisions (
+	id BIGSERIAL PRIMARY KEY,
+	guild_id BIGINT NOT NULL,
+	local_id BIGINT NOT NULL,
+
+	author_id BIGINT NOT NULL,
+	author_username TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+	data JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS custom_command_revisions_cmd_idx ON custom_command_revisions(guild_id, local_id);
 
 CREATE TABLE IF NOT EXISTS templates_user_database (
 	id BIGSERIAL PRIMARY KEY,
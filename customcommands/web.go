@@ -2,7 +2,9 @@ package customcommands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/jonas747/discordgo"
 	"github.com/jonas747/yagpdb/common"
 	"github.com/jonas747/yagpdb/common/pubsub"
 	"github.com/jonas747/yagpdb/customcommands/models"
@@ -17,6 +19,8 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -54,6 +58,11 @@ func (p *Plugin) InitWeb() {
 	subMux.Handle(pat.Get(""), getHandler)
 	subMux.Handle(pat.Get("/"), getHandler)
 
+	subMux.Handle(pat.Get("/cron_preview"), web.APIHandler(HandleCronPreview))
+
+	subMux.Handle(pat.Get("/export"), http.HandlerFunc(HandleExportCommands))
+	subMux.Handle(pat.Post("/import"), web.ControllerPostHandler(HandleImportCommands, getHandler, ImportCommandsData{}, "Imported custom commands"))
+
 	subMux.Handle(pat.Get("/groups/:group/"), web.ControllerHandler(HandleGetCommandsGroup, "cp_custom_commands"))
 	subMux.Handle(pat.Get("/groups/:group"), web.ControllerHandler(HandleGetCommandsGroup, "cp_custom_commands"))
 
@@ -62,6 +71,10 @@ func (p *Plugin) InitWeb() {
 	subMux.Handle(pat.Post("/commands/:cmd/update"), web.ControllerPostHandler(HandleUpdateCommand, getHandler, CustomCommand{}, "Updated a custom command"))
 	subMux.Handle(pat.Post("/commands/:cmd/delete"), web.ControllerPostHandler(HandleDeleteCommand, getHandler, nil, "Deleted a custom command"))
 
+	historyHandler := web.ControllerHandler(HandleCommandHistory, "cp_custom_commands_history")
+	subMux.Handle(pat.Get("/commands/:cmd/history"), historyHandler)
+	subMux.Handle(pat.Post("/commands/:cmd/history/:revision/restore"), web.ControllerPostHandler(HandleRestoreRevision, historyHandler, nil, "Restored a previous revision of a custom command"))
+
 	subMux.Handle(pat.Post("/creategroup"), web.ControllerPostHandler(HandleNewGroup, getHandler, GroupForm{}, "Created a new custom command group"))
 	subMux.Handle(pat.Post("/groups/:group/update"), web.ControllerPostHandler(HandleUpdateGroup, getGroupHandler, GroupForm{}, "Updated a custom command group"))
 	subMux.Handle(pat.Post("/groups/:group/delete"), web.ControllerPostHandler(HandleDeleteGroup, getHandler, nil, "Deleted a custom command group"))
@@ -87,6 +100,7 @@ func HandleGetCommandsGroup(w http.ResponseWriter, r *http.Request) (web.Templat
 func ServeGroupSelected(r *http.Request, templateData web.TemplateData, groupID int64, guildID int64) (web.TemplateData, error) {
 	templateData["GetCCIntervalType"] = tmplGetCCIntervalTriggerType
 	templateData["GetCCInterval"] = tmplGetCCInterval
+	templateData["GetCCCronPreview"] = tmplGetCCCronPreview
 
 	_, ok := templateData["CustomCommands"]
 	if !ok {
@@ -173,6 +187,8 @@ func HandleNewCommand(w http.ResponseWriter, r *http.Request) (web.TemplateData,
 		return templateData, err
 	}
 
+	saveRevisionFromContext(ctx, dbModel)
+
 	if dbModel.TriggerType == int(CommandTriggerInterval) {
 		// create, update or remove the next run time and scheduled event
 		err = UpdateCommandNextRunTime(dbModel, true)
@@ -219,11 +235,21 @@ func HandleUpdateCommand(w http.ResponseWriter, r *http.Request) (web.TemplateDa
 		}
 	}
 
-	_, err := dbModel.UpdateG(ctx, boil.Blacklist("last_run", "next_run", "local_id", "guild_id"))
+	// keep the original of commands that has no history yet before it's overwritten
+	err := SaveInitialRevision(ctx, activeGuild.ID, dbModel.LocalID)
+	if err != nil {
+		web.CtxLogger(ctx).WithError(err).WithField("guild", activeGuild.ID).Error("failed saving initial custom command revision")
+	}
+
+	updated, err := dbModel.UpdateG(ctx, boil.Blacklist("last_run", "next_run", "local_id", "guild_id"))
 	if err != nil {
 		return templateData, nil
 	}
 
+	if updated > 0 {
+		saveRevisionFromContext(ctx, dbModel)
+	}
+
 	// create, update or remove the next run time and scheduled event
 	if dbModel.TriggerType == int(CommandTriggerInterval) {
 		// need the last run time
@@ -269,11 +295,266 @@ func HandleDeleteCommand(w http.ResponseWriter, r *http.Request) (web.TemplateDa
 		return templateData, err
 	}
 
+	_, err = models.CustomCommandRevisions(qm.Where("guild_id = ? AND local_id = ?", cmd.GuildID, cmd.LocalID)).DeleteAll(ctx, common.PQ)
+	if err != nil {
+		return templateData, err
+	}
+
 	err = DelNextRunEvent(cmd.GuildID, cmd.LocalID)
 	common.LogIgnoreError(pubsub.Publish("custom_commands_clear_cache", activeGuild.ID, nil), "failed creating pubsub cache eviction event", web.CtxLogger(ctx).Data)
 	return templateData, err
 }
 
+// saveRevisionFromContext saves a revision of the command with the logged in user as the author
+func saveRevisionFromContext(ctx context.Context, cc *models.CustomCommand) {
+	user, ok := ctx.Value(common.ContextKeyUser).(*discordgo.User)
+	if !ok {
+		return
+	}
+
+	err := SaveRevision(ctx, cc, user)
+	if err != nil {
+		web.CtxLogger(ctx).WithError(err).WithField("guild", cc.GuildID).Error("failed saving custom command revision")
+	}
+}
+
+func findRevision(revisions []*models.CustomCommandRevision, idStr string) (int, bool) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	for i, v := range revisions {
+		if v.ID == id {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// HandleCommandHistory shows the revisions of a command, and the changes made in the selected one
+// (compared to the revision before it, or the one specified with compare)
+func HandleCommandHistory(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	cmdID, err := strconv.ParseInt(pat.Param(r, "cmd"), 10, 64)
+	if err != nil {
+		return templateData, err
+	}
+
+	cmd, err := models.CustomCommands(qm.Where("guild_id = ? AND local_id = ?", activeGuild.ID, cmdID)).OneG(ctx)
+	if err != nil {
+		return templateData, err
+	}
+
+	revisions, err := models.CustomCommandRevisions(qm.Where("guild_id = ? AND local_id = ?", activeGuild.ID, cmdID), qm.OrderBy("id desc")).AllG(ctx)
+	if err != nil {
+		return templateData, err
+	}
+
+	templateData["CC"] = cmd
+	templateData["Revisions"] = revisions
+	templateData["MaxRevisions"] = MaxCommandRevisions
+	if len(revisions) < 1 {
+		return templateData, nil
+	}
+
+	selectedIndex, _ := findRevision(revisions, r.URL.Query().Get("revision"))
+	selected := revisions[selectedIndex]
+
+	var compareTo *models.CustomCommandRevision
+	if i, ok := findRevision(revisions, r.URL.Query().Get("compare")); ok {
+		compareTo = revisions[i]
+	} else if selectedIndex+1 < len(revisions) {
+		compareTo = revisions[selectedIndex+1]
+	}
+
+	selectedSettings, err := revisionSettings(selected)
+	if err != nil {
+		return templateData, err
+	}
+
+	var oldLines []string
+	if compareTo != nil {
+		compareSettings, err := revisionSettings(compareTo)
+		if err != nil {
+			return templateData, err
+		}
+
+		oldLines = compareSettings.Lines()
+	}
+
+	templateData["SelectedRevision"] = selected
+	templateData["CompareRevision"] = compareTo
+	templateData["Diff"] = DiffLines(oldLines, selectedSettings.Lines())
+
+	return templateData, nil
+}
+
+// HandleRestoreRevision sets the responses, trigger and settings of the command back to a previous revision
+func HandleRestoreRevision(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	cmdID, err := strconv.ParseInt(pat.Param(r, "cmd"), 10, 64)
+	if err != nil {
+		return templateData, err
+	}
+
+	revisionID, err := strconv.ParseInt(pat.Param(r, "revision"), 10, 64)
+	if err != nil {
+		return templateData, err
+	}
+
+	cmd, err := models.CustomCommands(qm.Where("guild_id = ? AND local_id = ?", activeGuild.ID, cmdID)).OneG(ctx)
+	if err != nil {
+		return templateData, err
+	}
+
+	revision, err := models.CustomCommandRevisions(qm.Where("guild_id = ? AND local_id = ? AND id = ?", activeGuild.ID, cmdID, revisionID)).OneG(ctx)
+	if err != nil {
+		return templateData, err
+	}
+
+	settings, err := revisionSettings(revision)
+	if err != nil {
+		return templateData, err
+	}
+
+	settings.ApplyTo(cmd)
+
+	// check low interval limits
+	if cmd.TriggerType == int(CommandTriggerInterval) && cmd.TimeTriggerInterval < 10 {
+		ok, err := CheckIntervalLimits(ctx, activeGuild.ID, cmd.LocalID, templateData)
+		if err != nil || !ok {
+			return templateData, err
+		}
+	}
+
+	_, err = cmd.UpdateG(ctx, boil.Blacklist("last_run", "next_run", "local_id", "guild_id", "group_id"))
+	if err != nil {
+		return templateData, err
+	}
+
+	saveRevisionFromContext(ctx, cmd)
+
+	// create, update or remove the next run time and scheduled event
+	if cmd.TriggerType == int(CommandTriggerInterval) {
+		err = UpdateCommandNextRunTime(cmd, false)
+	} else {
+		err = DelNextRunEvent(activeGuild.ID, cmd.LocalID)
+	}
+
+	if err != nil {
+		logrus.WithError(err).WithField("guild", cmd.GuildID).Error("failed updating next custom command run time")
+	}
+
+	common.LogIgnoreError(pubsub.Publish("custom_commands_clear_cache", activeGuild.ID, nil), "failed creating pubsub cache eviction event", web.CtxLogger(ctx).Data)
+	return templateData, nil
+}
+
+// HandleExportCommands sends a export of a single command (?cmd=id), a group and its commands (?group=id) or everything as a file
+func HandleExportCommands(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	activeGuild, _ := web.GetBaseCPContextData(ctx)
+
+	var cmds []*models.CustomCommand
+	var groups []*models.CustomCommandGroup
+	var err error
+	filename := "custom-commands.json"
+
+	cmdID, _ := strconv.ParseInt(r.URL.Query().Get("cmd"), 10, 64)
+	groupID, _ := strconv.ParseInt(r.URL.Query().Get("group"), 10, 64)
+	switch {
+	case cmdID != 0:
+		cmds, err = models.CustomCommands(qm.Where("guild_id = ? AND local_id = ?", activeGuild.ID, cmdID)).AllG(ctx)
+		filename = fmt.Sprintf("custom-command-%d.json", cmdID)
+	case groupID != 0:
+		groups, err = models.CustomCommandGroups(qm.Where("guild_id = ? AND id = ?", activeGuild.ID, groupID)).AllG(ctx)
+		if err == nil {
+			cmds, err = models.CustomCommands(qm.Where("guild_id = ? AND group_id = ?", activeGuild.ID, groupID), qm.OrderBy("local_id asc")).AllG(ctx)
+		}
+		filename = fmt.Sprintf("custom-command-group-%d.json", groupID)
+	default:
+		groups, err = models.CustomCommandGroups(qm.Where("guild_id = ?", activeGuild.ID), qm.OrderBy("id asc")).AllG(ctx)
+		if err == nil {
+			cmds, err = models.CustomCommands(qm.Where("guild_id = ?", activeGuild.ID), qm.OrderBy("local_id asc")).AllG(ctx)
+		}
+	}
+
+	if err != nil {
+		web.CtxLogger(ctx).WithError(err).Error("failed retrieving custom commands for export")
+		http.Error(w, "Failed exporting custom commands", http.StatusInternalServerError)
+		return
+	}
+
+	if len(cmds) < 1 && len(groups) < 1 {
+		http.Error(w, "Nothing to export", http.StatusNotFound)
+		return
+	}
+
+	export := ExportCommands(activeGuild, cmds, groups)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(export)
+	if err != nil {
+		web.CtxLogger(ctx).WithError(err).Error("failed writing custom commands export")
+	}
+}
+
+type ImportCommandsData struct {
+	Data string `valid:",1,3000000"`
+}
+
+func HandleImportCommands(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+	data := ctx.Value(common.ContextKeyParsedForm).(*ImportCommandsData)
+
+	export, err := ParseCommandsExport([]byte(data.Data))
+	if err != nil {
+		return templateData, err
+	}
+
+	remap, roleMappings, channelMappings := web.ReadImportRemap(r.Form, activeGuild, &export.ExportedRefs)
+	if remap == nil {
+		// ask the user where to map the roles and channels that don't exist on this server
+		templateData["CCImportData"] = data.Data
+		templateData["CCImportNumCommands"] = len(export.Commands)
+		templateData["CCImportNumGroups"] = len(export.Groups)
+		templateData["CCImportRoles"] = roleMappings
+		templateData["CCImportChannels"] = channelMappings
+		templateData.AddAlerts(web.WarningAlert("The custom commands references roles or channels that does not exist on this server, select what to replace them with below to finish the import."))
+		return templateData, nil
+	}
+
+	cmds, ok, err := ImportCommands(ctx, activeGuild, templateData, export, remap)
+	if err != nil || !ok {
+		return templateData, err
+	}
+
+	for _, cmd := range cmds {
+		saveRevisionFromContext(ctx, cmd)
+
+		if cmd.TriggerType == int(CommandTriggerInterval) {
+			err = UpdateCommandNextRunTime(cmd, true)
+			if err != nil {
+				logrus.WithError(err).WithField("guild", cmd.GuildID).Error("failed updating next custom command run time")
+			}
+		}
+	}
+
+	common.LogIgnoreError(pubsub.Publish("custom_commands_clear_cache", activeGuild.ID, nil), "failed creating pubsub cache eviction event", web.CtxLogger(ctx).Data)
+	templateData.AddAlerts(web.SucessAlert(fmt.Sprintf("Imported %d custom command(s) and %d group(s)", len(cmds), len(export.Groups))))
+	return templateData, nil
+}
+
 // allow for max 5 triggers with intervals of less than 10 minutes
 func CheckIntervalLimits(ctx context.Context, guildID int64, cmdID int64, templateData web.TemplateData) (ok bool, err error) {
 	num, err := models.CustomCommands(qm.Where("guild_id = ? AND local_id != ? AND trigger_type = 5 AND time_trigger_interval < 10", guildID, cmdID)).CountG(ctx)
@@ -374,8 +655,18 @@ func TriggerTypeFromForm(str string) CommandTriggerType {
 		return CommandTriggerExact
 	case "command":
 		return CommandTriggerCommand
-	case "interval_minutes", "interval_hours":
+	case "interval_minutes", "interval_hours", "interval_cron":
 		return CommandTriggerInterval
+	case "reaction":
+		return CommandTriggerReaction
+	case "member_join":
+		return CommandTriggerMemberJoin
+	case "member_leave":
+		return CommandTriggerMemberLeave
+	case "role_added":
+		return CommandTriggerRoleAdded
+	case "role_removed":
+		return CommandTriggerRoleRemoved
 	default:
 		return CommandTriggerCommand
 
@@ -391,12 +682,16 @@ func CheckLimits(in ...string) bool {
 	return true
 }
 
-// returns 1 for hours, 0 for minutes, -1 otherwise
+// returns 2 for cron schedules, 1 for hours, 0 for minutes, -1 otherwise
 func tmplGetCCIntervalTriggerType(cc *models.CustomCommand) int {
 	if cc.TriggerType != int(CommandTriggerInterval) {
 		return -1
 	}
 
+	if cc.TimeTriggerCron != "" {
+		return 2
+	}
+
 	if (cc.TimeTriggerInterval % 60) == 0 {
 		return 1
 	}
@@ -413,6 +708,42 @@ func tmplGetCCInterval(cc *models.CustomCommand) int {
 	return cc.TimeTriggerInterval
 }
 
+// returns the next 5 run times of a cron scheduled command, formatted in its timezone
+func tmplGetCCCronPreview(cc *models.CustomCommand) []string {
+	runs, _ := cronPreview(cc.TimeTriggerCron, cc.TimeTriggerTimezone)
+	return runs
+}
+
+func cronPreview(expr, timezone string) ([]string, error) {
+	schedule, err := ParseCronSchedule(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := LoadCronLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := schedule.NextN(time.Now().In(loc), 5)
+	result := make([]string, len(runs))
+	for i, v := range runs {
+		result[i] = v.Format("Mon 2006-01-02 15:04 MST")
+	}
+
+	return result, nil
+}
+
+// HandleCronPreview returns the next run times of the cron schedule and timezone in the query, used to preview schedules while editing
+func HandleCronPreview(w http.ResponseWriter, r *http.Request) interface{} {
+	runs, err := cronPreview(r.URL.Query().Get("cron"), strings.TrimSpace(r.URL.Query().Get("timezone")))
+	if err != nil {
+		return web.NewPublicError(err.Error())
+	}
+
+	return map[string]interface{}{"ok": true, "runs": runs}
+}
+
 var _ web.PluginWithServerHomeWidget = (*Plugin)(nil)
 
 func (p *Plugin) LoadServerHomeWidget(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
@@ -0,0 +1,300 @@
+package moderation
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/bot"
+	"github.com/jonas747/yagpdb/bot/eventsystem"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/web"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type AppealStatus int
+
+const (
+	AppealPending AppealStatus = iota
+	AppealApproved
+	AppealDenied
+)
+
+func (s AppealStatus) String() string {
+	switch s {
+	case AppealPending:
+		return "Pending"
+	case AppealApproved:
+		return "Approved"
+	case AppealDenied:
+		return "Denied"
+	}
+
+	return "Unknown"
+}
+
+const (
+	AppealApproveEmoji = "✅"
+	AppealDenyEmoji    = "❌"
+
+	// How long a user has to wait before they can appeal again after being denied
+	AppealDeniedCooldown = time.Hour * 24 * 7
+)
+
+// BanAppeal is a appeal submitted through the control panel by a banned user
+type BanAppeal struct {
+	common.SmallModel
+	GuildID int64 `gorm:"index"`
+
+	UserID          int64 `gorm:"index"`
+	UsernameDiscrim string
+	Text            string
+
+	Status     AppealStatus
+	ReviewerID int64
+
+	// The message in the appeals channel
+	ChannelID int64
+	MessageID int64 `gorm:"index"`
+}
+
+func (a *BanAppeal) TableName() string {
+	return "moderation_ban_appeals"
+}
+
+// AppealURL returns the link to the appeal form of the guild
+func AppealURL(guildID int64) string {
+	return web.BaseURL() + "/appeal/" + discordgo.StrID(guildID)
+}
+
+// LatestBanCase returns the latest ban case of the user, or nil if they were unbanned after it or have never been banned
+func LatestBanCase(guildID, userID int64) (*ModerationCase, error) {
+	var c ModerationCase
+	err := common.GORM.Where("guild_id = ? AND user_id = ? AND action IN (?)", guildID, userID, []string{MABanned.Prefix, MAUnbanned.Prefix}).Order("case_number desc").First(&c).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if c.Action != MABanned.Prefix {
+		return nil, nil
+	}
+
+	return &c, nil
+}
+
+// LatestAppeal returns the latest appeal by the user, or nil if they have never appealed
+func LatestAppeal(guildID, userID int64) (*BanAppeal, error) {
+	var appeal BanAppeal
+	err := common.GORM.Where("guild_id = ? AND user_id = ?", guildID, userID).Order("id desc").First(&appeal).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &appeal, nil
+}
+
+// CanAppeal returns true if the user is able to submit a appeal, otherwise a explanation is returned
+func CanAppeal(guildID, userID int64, banCase *ModerationCase) (bool, string, error) {
+	if banCase == nil {
+		return false, "You're not banned on this server", nil
+	}
+
+	latest, err := LatestAppeal(guildID, userID)
+	if err != nil || latest == nil {
+		return err == nil, "", err
+	}
+
+	// appeals from earlier bans don't count
+	if latest.CreatedAt.Before(banCase.CreatedAt) {
+		return true, "", nil
+	}
+
+	switch latest.Status {
+	case AppealPending:
+		return false, "Your appeal is pending, wait for a moderator to review it", nil
+	case AppealDenied:
+		if time.Since(latest.UpdatedAt) < AppealDeniedCooldown {
+			wait := common.HumanizeDuration(common.DurationPrecisionHours, AppealDeniedCooldown-time.Since(latest.UpdatedAt))
+			return false, "Your appeal was denied, you can appeal again in " + wait, nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// CreateAppeal stores the appeal and posts it in the appeals channel for moderators to review
+func CreateAppeal(config *Config, guildID int64, user *discordgo.User, banCase *ModerationCase, text string) (*BanAppeal, error) {
+	channelID := config.IntAppealsChannel()
+	if channelID == 0 {
+		return nil, web.NewPublicError("This server has not set up a appeals channel")
+	}
+
+	appeal := &BanAppeal{
+		GuildID:         guildID,
+		UserID:          user.ID,
+		UsernameDiscrim: user.Username + "#" + user.Discriminator,
+		Text:            text,
+		ChannelID:       channelID,
+	}
+
+	err := common.GORM.Create(appeal).Error
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := common.BotSession.ChannelMessageSendEmbed(channelID, appealEmbed(appeal, banCase, nil))
+	if err != nil {
+		common.GORM.Delete(appeal)
+		return nil, err
+	}
+
+	appeal.MessageID = msg.ID
+	err = common.GORM.Model(appeal).Update("message_id", msg.ID).Error
+	if err != nil {
+		return appeal, err
+	}
+
+	for _, emoji := range []string{AppealApproveEmoji, AppealDenyEmoji} {
+		err = common.BotSession.MessageReactionAdd(channelID, msg.ID, emoji)
+		if err != nil {
+			return appeal, err
+		}
+	}
+
+	return appeal, nil
+}
+
+func appealEmbed(appeal *BanAppeal, banCase *ModerationCase, reviewer *discordgo.User) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Ban appeal from %s (ID %d)", appeal.UsernameDiscrim, appeal.UserID),
+		Description: common.CutStringShort(appeal.Text, 2000),
+		Color:       0xfca253,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("React with %s to unban or %s to deny the appeal", AppealApproveEmoji, AppealDenyEmoji),
+		},
+	}
+
+	if banCase != nil {
+		reason := banCase.Reason
+		if reason == "" {
+			reason = "(no reason specified)"
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("Case #%d", banCase.CaseNumber),
+			Value: common.CutStringShort(fmt.Sprintf("Banned by %s: %s", banCase.AuthorUsernameDiscrim, reason), 1000),
+		})
+	}
+
+	if appeal.Status != AppealPending && reviewer != nil {
+		embed.Footer.Text = fmt.Sprintf("%s by %s#%s", appeal.Status, reviewer.Username, reviewer.Discriminator)
+		if appeal.Status == AppealApproved {
+			embed.Color = MAUnbanned.Color
+		} else {
+			embed.Color = MABanned.Color
+		}
+	}
+
+	return embed
+}
+
+// HandleAppealReaction approves or denies appeals when moderators react to them in the appeals channel
+func HandleAppealReaction(evt *eventsystem.EventData) {
+	ra := evt.MessageReactionAdd()
+	if ra.GuildID == 0 || ra.UserID == common.BotUser.ID || ra.Emoji.ID != 0 {
+		return
+	}
+
+	if ra.Emoji.Name != AppealApproveEmoji && ra.Emoji.Name != AppealDenyEmoji {
+		return
+	}
+
+	config, err := GetConfig(ra.GuildID)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed retrieving config")
+		return
+	}
+
+	// appeals are only posted in the appeals channel, avoid looking up every reaction in the database
+	if ra.ChannelID != config.IntAppealsChannel() {
+		return
+	}
+
+	var appeal BanAppeal
+	err = common.GORM.Where("guild_id = ? AND channel_id = ? AND message_id = ? AND status = ?", ra.GuildID, ra.ChannelID, ra.MessageID, AppealPending).First(&appeal).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed retrieving appeal")
+		}
+		return
+	}
+
+	ms, err := bot.GetMember(ra.GuildID, ra.UserID)
+	if err != nil || ms == nil {
+		return
+	}
+
+	if hasPerms, _ := bot.AdminOrPermMS(ms, ra.ChannelID, discordgo.PermissionBanMembers); !hasPerms {
+		return
+	}
+
+	err = ResolveAppeal(config, &appeal, ms.DGoUser(), ra.Emoji.Name == AppealApproveEmoji)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed resolving appeal")
+	}
+}
+
+// ResolveAppeal approves or denies the appeal, if approved the user is unbanned
+func ResolveAppeal(config *Config, appeal *BanAppeal, reviewer *discordgo.User, approve bool) error {
+	status := AppealDenied
+	if approve {
+		status = AppealApproved
+	}
+
+	// make sure it's only resolved once, if multiple moderators react at the same time
+	result := common.GORM.Model(appeal).Where("status = ?", AppealPending).Updates(map[string]interface{}{"status": status, "reviewer_id": reviewer.ID})
+	if result.Error != nil || result.RowsAffected < 1 {
+		return result.Error
+	}
+
+	appeal.Status = status
+	appeal.ReviewerID = reviewer.ID
+
+	banCase, err := LatestBanCase(appeal.GuildID, appeal.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = common.BotSession.ChannelMessageEditEmbed(appeal.ChannelID, appeal.MessageID, appealEmbed(appeal, banCase, reviewer))
+	if err != nil {
+		logrus.WithError(err).WithField("guild", appeal.GuildID).Error("failed updating appeal message")
+	}
+
+	if !approve {
+		return nil
+	}
+
+	target := &discordgo.User{ID: appeal.UserID, Username: appeal.UsernameDiscrim, Discriminator: "????"}
+	if banCase != nil {
+		target.Username, target.Discriminator = splitUsernameDiscrim(banCase.UsernameDiscrim)
+	}
+
+	reason := "Ban appeal approved: " + common.CutStringShort(appeal.Text, 1000)
+	return UnbanUser(config, appeal.GuildID, reviewer, reason, target)
+}
+
+func splitUsernameDiscrim(usernameDiscrim string) (username, discrim string) {
+	if len(usernameDiscrim) < 6 || usernameDiscrim[len(usernameDiscrim)-5] != '#' {
+		return usernameDiscrim, "????"
+	}
+
+	return usernameDiscrim[:len(usernameDiscrim)-5], usernameDiscrim[len(usernameDiscrim)-4:]
+}
@@ -0,0 +1,269 @@
+package moderation
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/bot"
+	"github.com/jonas747/yagpdb/bot/eventsystem"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/common/pubsub"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Max number of servers a server can share bans with
+	MaxBanSharePartners = 10
+
+	SharedBanApplyEmoji = "✅"
+)
+
+// BanSharePartner is one side of a ban sharing trust, bans are only shared between 2 servers if both have added eachother.
+type BanSharePartner struct {
+	common.SmallModel
+	GuildID        int64 `gorm:"unique_index:idx_ban_share_guild_partner"`
+	PartnerGuildID int64 `gorm:"unique_index:idx_ban_share_guild_partner;index"`
+
+	// Apply shared bans from the partner right away instead of posting a suggestion in the action channel
+	AutoApply bool
+}
+
+func (b *BanSharePartner) TableName() string {
+	return "moderation_ban_share_partners"
+}
+
+// SharedBanSuggestion is a shared ban from a partner posted in the action channel, waiting for a moderator to apply it
+type SharedBanSuggestion struct {
+	common.SmallModel
+	GuildID       int64 `gorm:"index"`
+	SourceGuildID int64
+
+	UserID          int64
+	UsernameDiscrim string
+	Reason          string
+
+	Applied   bool
+	ChannelID int64
+	MessageID int64 `gorm:"index"`
+}
+
+func (s *SharedBanSuggestion) TableName() string {
+	return "moderation_shared_ban_suggestions"
+}
+
+// SharedBanData is sent through pubsub to the partner servers, so it reaches whichever shard has them
+type SharedBanData struct {
+	SourceGuildID         int64  `json:"source_guild_id"`
+	SourceGuildName       string `json:"source_guild_name"`
+	AuthorUsernameDiscrim string `json:"author_username_discrim"`
+
+	UserID          int64  `json:"user_id"`
+	UsernameDiscrim string `json:"username_discrim"`
+	Reason          string `json:"reason"`
+}
+
+// GetBanSharePartners returns the partners of the guild, if mutualOnly is set only the ones that has added the guild back are returned
+func GetBanSharePartners(guildID int64, mutualOnly bool) ([]*BanSharePartner, error) {
+	query := common.GORM.Where("guild_id = ?", guildID)
+	if mutualOnly {
+		query = query.Where("partner_guild_id IN (SELECT guild_id FROM moderation_ban_share_partners WHERE partner_guild_id = ?)", guildID)
+	}
+
+	var result []*BanSharePartner
+	err := query.Order("id asc").Find(&result).Error
+	return result, err
+}
+
+// GetBanSharePartner returns the guilds side of the trust with the partner, or nil if they're not mutual partners
+func GetBanSharePartner(guildID, partnerGuildID int64) (*BanSharePartner, error) {
+	var partner BanSharePartner
+	err := common.GORM.Where("guild_id = ? AND partner_guild_id = ?", guildID, partnerGuildID).
+		Where("EXISTS (SELECT 1 FROM moderation_ban_share_partners WHERE guild_id = ? AND partner_guild_id = ?)", partnerGuildID, guildID).
+		First(&partner).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &partner, nil
+}
+
+// ShareBan sends the ban to all mutual partners of the guild
+func ShareBan(guildID int64, guildName string, author *discordgo.User, reason string, user *discordgo.User) (int, error) {
+	partners, err := GetBanSharePartners(guildID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	data := &SharedBanData{
+		SourceGuildID:         guildID,
+		SourceGuildName:       guildName,
+		AuthorUsernameDiscrim: author.Username + "#" + author.Discriminator,
+		UserID:                user.ID,
+		UsernameDiscrim:       user.Username + "#" + user.Discriminator,
+		Reason:                reason,
+	}
+
+	for _, v := range partners {
+		err = pubsub.Publish("mod_shared_ban", v.PartnerGuildID, data)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(partners), nil
+}
+
+func HandleSharedBanEvent(evt *pubsub.Event) {
+	data := evt.Data.(*SharedBanData)
+
+	// pubsub handlers are ran in sequence, don't block the others while banning
+	go func() {
+		err := applySharedBan(evt.TargetGuildInt, data)
+		if err != nil {
+			logrus.WithError(err).WithField("guild", evt.TargetGuildInt).WithField("source_guild", data.SourceGuildID).Error("[moderation] failed handling shared ban")
+		}
+	}()
+}
+
+func (d *SharedBanData) banReason() string {
+	reason := d.Reason
+	if reason == "" {
+		reason = "(no reason specified)"
+	}
+
+	return common.CutStringShort(fmt.Sprintf("Shared ban from %s by %s: %s", d.SourceGuildName, d.AuthorUsernameDiscrim, reason), 500)
+}
+
+// applySharedBan bans the user right away if the guild has auto apply enabled for the source, otherwise a suggestion is posted in the action channel
+func applySharedBan(guildID int64, data *SharedBanData) error {
+	partner, err := GetBanSharePartner(guildID, data.SourceGuildID)
+	if err != nil || partner == nil {
+		// no longer partners
+		return err
+	}
+
+	config, err := GetConfig(guildID)
+	if err != nil {
+		return err
+	}
+
+	username, discrim := splitUsernameDiscrim(data.UsernameDiscrim)
+	target := &discordgo.User{ID: data.UserID, Username: username, Discriminator: discrim}
+
+	if partner.AutoApply {
+		return BanUser(config, guildID, 0, common.BotUser, data.banReason(), target)
+	}
+
+	channelID := config.IntActionChannel()
+	if channelID == 0 {
+		return nil
+	}
+
+	suggestion := &SharedBanSuggestion{
+		GuildID:         guildID,
+		SourceGuildID:   data.SourceGuildID,
+		UserID:          data.UserID,
+		UsernameDiscrim: data.UsernameDiscrim,
+		Reason:          data.banReason(),
+		ChannelID:       channelID,
+	}
+
+	err = common.GORM.Create(suggestion).Error
+	if err != nil {
+		return err
+	}
+
+	msg, err := common.BotSession.ChannelMessageSendEmbed(channelID, sharedBanEmbed(suggestion, nil))
+	if err != nil {
+		common.GORM.Delete(suggestion)
+		return err
+	}
+
+	err = common.GORM.Model(suggestion).Update("message_id", msg.ID).Error
+	if err != nil {
+		return err
+	}
+
+	return common.BotSession.MessageReactionAdd(channelID, msg.ID, SharedBanApplyEmoji)
+}
+
+func sharedBanEmbed(suggestion *SharedBanSuggestion, appliedBy *discordgo.User) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Shared ban of %s (ID %d)", suggestion.UsernameDiscrim, suggestion.UserID),
+		Description: suggestion.Reason,
+		Color:       0xfca253,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("React with %s to ban them here as well", SharedBanApplyEmoji),
+		},
+	}
+
+	if appliedBy != nil {
+		embed.Color = MABanned.Color
+		embed.Footer.Text = fmt.Sprintf("Applied by %s#%s", appliedBy.Username, appliedBy.Discriminator)
+	}
+
+	return embed
+}
+
+// HandleSharedBanReaction applies shared ban suggestions when a moderator reacts to them
+func HandleSharedBanReaction(evt *eventsystem.EventData) {
+	ra := evt.MessageReactionAdd()
+	if ra.GuildID == 0 || ra.UserID == common.BotUser.ID || ra.Emoji.ID != 0 || ra.Emoji.Name != SharedBanApplyEmoji {
+		return
+	}
+
+	config, err := GetConfig(ra.GuildID)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed retrieving config")
+		return
+	}
+
+	// suggestions are only posted in the action channel, avoid looking up every reaction in the database
+	if ra.ChannelID != config.IntActionChannel() {
+		return
+	}
+
+	var suggestion SharedBanSuggestion
+	err = common.GORM.Where("guild_id = ? AND channel_id = ? AND message_id = ? AND applied = false", ra.GuildID, ra.ChannelID, ra.MessageID).First(&suggestion).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed retrieving shared ban suggestion")
+		}
+		return
+	}
+
+	ms, err := bot.GetMember(ra.GuildID, ra.UserID)
+	if err != nil || ms == nil {
+		return
+	}
+
+	if hasPerms, _ := bot.AdminOrPermMS(ms, ra.ChannelID, discordgo.PermissionBanMembers); !hasPerms {
+		return
+	}
+
+	// make sure it's only applied once, if multiple moderators react at the same time
+	result := common.GORM.Model(&suggestion).Where("applied = false").Update("applied", true)
+	if result.Error != nil || result.RowsAffected < 1 {
+		return
+	}
+
+	username, discrim := splitUsernameDiscrim(suggestion.UsernameDiscrim)
+	target := &discordgo.User{ID: suggestion.UserID, Username: username, Discriminator: discrim}
+
+	author := ms.DGoUser()
+	err = BanUser(config, ra.GuildID, 0, author, suggestion.Reason, target)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed applying shared ban")
+		common.GORM.Model(&suggestion).Update("applied", false)
+		return
+	}
+
+	_, err = common.BotSession.ChannelMessageEditEmbed(suggestion.ChannelID, suggestion.MessageID, sharedBanEmbed(&suggestion, author))
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed updating shared ban message")
+	}
+}
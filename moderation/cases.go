@@ -0,0 +1,218 @@
+package moderation
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// ModerationCase is a record of a single moderation action, every action performed through the bot
+// or picked up from the audit log gets a case with a incrementing per guild case number
+type ModerationCase struct {
+	common.SmallModel
+
+	GuildID    int64 `gorm:"unique_index:idx_moderation_cases_guild_case"`
+	CaseNumber int64 `gorm:"unique_index:idx_moderation_cases_guild_case"`
+
+	// The prefix of the ModlogAction, e.g "Banned"
+	Action string
+
+	UserID                int64 `gorm:"index"`
+	UsernameDiscrim       string
+	AuthorID              int64
+	AuthorUsernameDiscrim string
+
+	Reason   string
+	Duration time.Duration
+	LogsLink string
+
+	// Set if the case was created by a automod rule
+	AutomodTrigger string
+
+	// The modlog message of this case, if any
+	ModlogChannelID int64
+	ModlogMessageID int64
+}
+
+func (c *ModerationCase) TableName() string {
+	return "moderation_cases"
+}
+
+// ModlogAction returns the modlog action this case was created from
+func (c *ModerationCase) ModlogAction() ModlogAction {
+	for _, v := range []ModlogAction{MAMute, MAUnmute, MAKick, MABanned, MAUnbanned, MAWarned, MAQuarantine, MAUnquarantine} {
+		if v.Prefix == c.Action {
+			return v
+		}
+	}
+
+	return ModlogAction{Prefix: c.Action, Color: 0x53fcf9}
+}
+
+// CreateCase creates a new case and posts it to the modlog if postModlog is true and a action channel is set up
+func CreateCase(config *Config, guildID int64, author *discordgo.User, action ModlogAction, target *discordgo.User, reason, logLink string, duration time.Duration, postModlog bool) (*ModerationCase, error) {
+	config, err := getConfigIfNotSet(guildID, config)
+	if err != nil {
+		return nil, common.ErrWithCaller(err)
+	}
+
+	caseNumber, err := common.GenLocalIncrID(guildID, "moderation_cases")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ModerationCase{
+		GuildID:         guildID,
+		CaseNumber:      caseNumber,
+		Action:          action.Prefix,
+		UserID:          target.ID,
+		UsernameDiscrim: target.Username + "#" + target.Discriminator,
+		Reason:          reason,
+		Duration:        duration,
+		LogsLink:        logLink,
+		AutomodTrigger:  config.AutomodTrigger,
+	}
+
+	if author != nil {
+		c.AuthorID = author.ID
+		c.AuthorUsernameDiscrim = author.Username + "#" + author.Discriminator
+	}
+
+	err = common.GORM.Create(c).Error
+	if err != nil {
+		return nil, common.ErrWithCaller(err)
+	}
+
+	if !postModlog || config.IntActionChannel() == 0 {
+		return c, nil
+	}
+
+	msg, err := createModlogEmbed(config.IntActionChannel(), author, action, target, reason, logLink, caseNumber)
+	if err != nil {
+		return c, err
+	}
+
+	c.ModlogChannelID = msg.ChannelID
+	c.ModlogMessageID = msg.ID
+	err = common.GORM.Model(c).Updates(map[string]interface{}{"modlog_channel_id": c.ModlogChannelID, "modlog_message_id": c.ModlogMessageID}).Error
+	return c, err
+}
+
+// FindCase returns the case with the specified number, or nil if it does not exist
+func FindCase(guildID int64, caseNumber int64) (*ModerationCase, error) {
+	var c ModerationCase
+	err := common.GORM.Where("guild_id = ? AND case_number = ?", guildID, caseNumber).First(&c).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// UpdateCaseReason updates the reason of the case along with the modlog entry for it,
+// if the case has no author (e.g it was picked up from the audit log without one) then it's also set
+func UpdateCaseReason(c *ModerationCase, author *discordgo.User, reason string) error {
+	updates := map[string]interface{}{"reason": reason}
+	c.Reason = reason
+	if c.AuthorID == 0 {
+		c.AuthorID = author.ID
+		c.AuthorUsernameDiscrim = author.Username + "#" + author.Discriminator
+		updates["author_id"] = c.AuthorID
+		updates["author_username_discrim"] = c.AuthorUsernameDiscrim
+	}
+
+	err := common.GORM.Model(c).Updates(updates).Error
+	if err != nil {
+		return err
+	}
+
+	if c.ModlogMessageID == 0 {
+		return nil
+	}
+
+	msg, err := common.BotSession.ChannelMessage(c.ModlogChannelID, c.ModlogMessageID)
+	if err != nil {
+		if common.IsDiscordErr(err, discordgo.ErrCodeUnknownMessage, discordgo.ErrCodeUnknownChannel) {
+			// modlog entry was deleted, the case itself is still updated
+			return nil
+		}
+
+		return err
+	}
+
+	if len(msg.Embeds) < 1 {
+		return nil
+	}
+
+	embed := msg.Embeds[0]
+	updateEmbedReason(author, reason, embed)
+	_, err = common.BotSession.ChannelMessageEditEmbed(c.ModlogChannelID, msg.ID, embed)
+	return err
+}
+
+// CaseEmbed returns a embed displaying the case
+func CaseEmbed(c *ModerationCase) *discordgo.MessageEmbed {
+	action := c.ModlogAction()
+
+	author := c.AuthorUsernameDiscrim
+	if c.AuthorID == 0 {
+		author = "Unknown"
+	} else {
+		author += fmt.Sprintf(" (ID %d)", c.AuthorID)
+	}
+
+	reason := c.Reason
+	if reason == "" {
+		reason = "(no reason specified)"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:     fmt.Sprintf("Case #%d: %s%s", c.CaseNumber, action.Emoji, action.Prefix),
+		Color:     action.Color,
+		Timestamp: c.CreatedAt.Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			&discordgo.MessageEmbedField{Name: "User", Value: fmt.Sprintf("%s (ID %d)", c.UsernameDiscrim, c.UserID), Inline: true},
+			&discordgo.MessageEmbedField{Name: "Moderator", Value: author, Inline: true},
+			&discordgo.MessageEmbedField{Name: "Reason", Value: common.CutStringShort(reason, 1000)},
+		},
+	}
+
+	if c.Duration > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Duration", Value: common.HumanizeDuration(common.DurationPrecisionMinutes, c.Duration), Inline: true})
+	}
+
+	if c.AutomodTrigger != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Automod", Value: c.AutomodTrigger, Inline: true})
+	}
+
+	var links []string
+	if c.LogsLink != "" {
+		links = append(links, "[Logs]("+c.LogsLink+")")
+	}
+
+	if c.ModlogMessageID != 0 {
+		links = append(links, fmt.Sprintf("[Modlog entry](https://discordapp.com/channels/%d/%d/%d)", c.GuildID, c.ModlogChannelID, c.ModlogMessageID))
+	}
+
+	if len(links) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Links", Value: strings.Join(links, " - ")})
+	}
+
+	return embed
+}
+
+// createCaseLogErr is a helper for places that can't return the error, such as event handlers
+func createCaseLogErr(config *Config, guildID int64, author *discordgo.User, action ModlogAction, target *discordgo.User, reason string, postModlog bool) {
+	_, err := CreateCase(config, guildID, author, action, target, reason, "", 0, postModlog)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", guildID).Error("Failed creating " + action.Prefix + " case")
+	}
+}
@@ -0,0 +1,253 @@
+package moderation
+
+import (
+	"fmt"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/dstate"
+	"github.com/jonas747/yagpdb/commands"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Max number of messages the clean command can delete, anything above 100 is done in the background
+	MaxCleanNum = 1000
+
+	// Max number of messages looked through when cleaning in the background
+	maxCleanScan = 10000
+
+	// Can only bulk delete messages up to 2 weeks (but add 1 minute buffer account for time sync issues and other smallies)
+	maxBulkDeleteAge = (time.Hour * 24 * 14) - time.Minute
+)
+
+var cleanLinkRegex = regexp.MustCompile(`(?i)(https?://\S|www\.\S)`)
+
+func RedisKeyCleanLock(channelID int64) string {
+	return "moderation_clean_lock:" + discordgo.StrID(channelID)
+}
+
+// CleanFilter decides which messages are deleted by the clean command
+type CleanFilter struct {
+	User   int64
+	Regex  *regexp.Regexp
+	MaxAge time.Duration
+
+	Bots        bool
+	Attachments bool
+	Links       bool // links or invites
+	Embeds      bool
+
+	// Only messages between these message ID's (inclusive), 0 for no limit
+	From int64
+	To   int64
+
+	// Inverse inverts the user, regex, bots, attachments, links and embeds filters
+	Inverse bool
+}
+
+// ContentFiltered returns true if any of the filters affected by Inverse is set
+func (f *CleanFilter) ContentFiltered() bool {
+	return f.User != 0 || f.Regex != nil || f.Bots || f.Attachments || f.Links || f.Embeds
+}
+
+// Filtered returns true if any filter is set
+func (f *CleanFilter) Filtered() bool {
+	return f.ContentFiltered() || f.MaxAge != 0 || f.From != 0 || f.To != 0
+}
+
+// Match returns true if the message should be deleted, it does not check the bulk delete age limit
+func (f *CleanFilter) Match(msg *dstate.MessageState, now time.Time) bool {
+	if f.MaxAge != 0 && now.Sub(msg.ParsedCreated) > f.MaxAge {
+		return false
+	}
+
+	if (f.From != 0 && msg.ID < f.From) || (f.To != 0 && msg.ID > f.To) {
+		return false
+	}
+
+	return f.matchContent(msg) != f.Inverse
+}
+
+func (f *CleanFilter) matchContent(msg *dstate.MessageState) bool {
+	if f.User != 0 && (msg.Author == nil || msg.Author.ID != f.User) {
+		return false
+	}
+
+	if f.Bots && (msg.Author == nil || !msg.Author.Bot) {
+		return false
+	}
+
+	if f.Attachments && len(msg.Attachments) < 1 {
+		return false
+	}
+
+	if f.Embeds && len(msg.Embeds) < 1 {
+		return false
+	}
+
+	if f.Links && !cleanLinkRegex.MatchString(msg.Content) && common.ContainsInvite(msg.Content, true, true) == nil {
+		return false
+	}
+
+	if f.Regex != nil && !f.Regex.MatchString(msg.Content) {
+		return false
+	}
+
+	return true
+}
+
+// pastCleanRange returns true if the message and everything before it is out of range for the filter,
+// used to stop paging through the history early
+func (f *CleanFilter) pastCleanRange(msg *dstate.MessageState, now time.Time) bool {
+	if now.Sub(msg.ParsedCreated) > maxBulkDeleteAge {
+		return true
+	}
+
+	if f.MaxAge != 0 && now.Sub(msg.ParsedCreated) > f.MaxAge {
+		return true
+	}
+
+	return f.From != 0 && msg.ID < f.From
+}
+
+// parseCleanMessageID parses a message link or ID, links have to point to the current channel
+func parseCleanMessageID(channelID int64, str string) (int64, error) {
+	str = strings.Trim(strings.TrimSpace(str), "<>")
+
+	if m := messageLinkRegex.FindStringSubmatch(str); m != nil {
+		if m[2] != discordgo.StrID(channelID) {
+			return 0, commands.NewPublicError("That message is not in this channel")
+		}
+
+		str = m[3]
+	}
+
+	messageID, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, commands.NewPublicError("Invalid message, specify either a message link or the ID of a message in this channel")
+	}
+
+	return messageID, nil
+}
+
+func deleteMessageIDs(channelID int64, ids []int64) error {
+	if len(ids) < 1 {
+		return nil
+	} else if len(ids) == 1 {
+		return common.BotSession.ChannelMessageDelete(channelID, ids[0])
+	}
+
+	return common.BotSession.ChannelMessagesBulkDelete(channelID, ids)
+}
+
+// CleanMessagesPaged pages through the channel history before the specified message ID, deleting messages matching the filter
+// in batches of up to 100 until deleteNum is reached, the end of the range is reached or maxCleanScan messages has been looked through.
+// progress is called after every batch
+func CleanMessagesPaged(channelID int64, filter *CleanFilter, before int64, deleteNum int, progress func(deleted, scanned int)) (int, error) {
+	if filter.To != 0 && (before == 0 || filter.To < before) {
+		before = filter.To + 1
+	}
+
+	deleted := 0
+	scanned := 0
+	now := time.Now()
+	for deleted < deleteNum && scanned < maxCleanScan {
+		msgs, err := common.BotSession.ChannelMessages(channelID, 100, before, 0, 0)
+		if err != nil {
+			return deleted, err
+		}
+
+		if len(msgs) < 1 {
+			break
+		}
+
+		// Oldest message is last
+		before = msgs[len(msgs)-1].ID
+		scanned += len(msgs)
+
+		toDelete := make([]int64, 0, len(msgs))
+		reachedEnd := len(msgs) < 100
+		for _, m := range msgs {
+			ms := dstate.MessageStateFromMessage(m)
+			if filter.pastCleanRange(ms, now) {
+				reachedEnd = true
+				break
+			}
+
+			if !filter.Match(ms, now) {
+				continue
+			}
+
+			toDelete = append(toDelete, m.ID)
+			if deleted+len(toDelete) >= deleteNum {
+				break
+			}
+		}
+
+		err = deleteMessageIDs(channelID, toDelete)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += len(toDelete)
+		if progress != nil {
+			progress(deleted, scanned)
+		}
+
+		if reachedEnd {
+			break
+		}
+	}
+
+	return deleted, nil
+}
+
+// startBackgroundClean deletes up to deleteNum messages before (and including) the triggering message in the background,
+// posting a progress message in the channel that is kept updated
+func startBackgroundClean(guildID, channelID, triggerID int64, author *discordgo.User, filter *CleanFilter, deleteNum int) (interface{}, error) {
+	locked, err := common.TryLockRedisKey(RedisKeyCleanLock(channelID), 60*30)
+	if err != nil {
+		return nil, err
+	}
+
+	if !locked {
+		return "There's already a clean in progress in this channel", nil
+	}
+
+	progressMsg, err := common.BotSession.ChannelMessageSend(channelID, fmt.Sprintf("Cleaning... deleted 0/%d message(s)", deleteNum))
+	if err != nil {
+		common.UnlockRedisKey(RedisKeyCleanLock(channelID))
+		return nil, err
+	}
+
+	go func() {
+		defer common.UnlockRedisKey(RedisKeyCleanLock(channelID))
+
+		// the progress message is newer than the trigger so it wont be included
+		numDeleted, err := CleanMessagesPaged(channelID, filter, triggerID+1, deleteNum, func(deleted, scanned int) {
+			common.BotSession.ChannelMessageEdit(channelID, progressMsg.ID,
+				fmt.Sprintf("Cleaning... deleted %d/%d message(s), looked through %d", deleted, deleteNum, scanned))
+		})
+
+		if numDeleted > 0 {
+			logClean(guildID, channelID, author, numDeleted)
+		}
+
+		resp := fmt.Sprintf("Deleted %d message(s)! :')", numDeleted)
+		if err != nil {
+			logrus.WithError(err).WithField("guild", guildID).Error("[moderation] failed cleaning messages")
+			resp = fmt.Sprintf("Deleted %d message(s) before running into an error", numDeleted)
+		}
+
+		common.BotSession.ChannelMessageEdit(channelID, progressMsg.ID, resp)
+
+		time.Sleep(time.Second * 5)
+		common.BotSession.ChannelMessageDelete(channelID, progressMsg.ID)
+	}()
+
+	return nil, nil
+}
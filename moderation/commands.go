@@ -16,6 +16,14 @@ import (
 	"github.com/jonas747/yagpdb/commands"
 	"github.com/jonas747/yagpdb/common"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Anything below this is treated as a case number by the reason command, and anything above as a modlog message ID
+	minModlogMessageID = 1000000000000000
+
+	casesPerPage = 15
 )
 
 func MBaseCmd(cmdData *dcmd.Data, targetID int64) (config *Config, targetUser *discordgo.User, err error) {
@@ -118,18 +126,20 @@ func GenericCmdResp(action ModlogAction, target *discordgo.User, duration time.D
 
 var ModerationCommands = []*commands.YAGCommand{
 	&commands.YAGCommand{
-		CustomEnabled: true,
-		CmdCategory:   commands.CategoryModeration,
-		Name:          "Ban",
-		Aliases:       []string{"banid"},
-		Description:   "Bans a member, specify a duration with -d",
-		RequiredArgs:  1,
+		CustomEnabled:   true,
+		CmdCategory:     commands.CategoryModeration,
+		Name:            "Ban",
+		Aliases:         []string{"banid"},
+		Description:     "Bans a member, specify a duration with -d",
+		LongDescription: "Use -shared to also share the ban with the servers this server shares bans with (set up in the control panel)",
+		RequiredArgs:    1,
 		Arguments: []*dcmd.ArgDef{
 			&dcmd.ArgDef{Name: "User", Type: dcmd.UserID},
 			&dcmd.ArgDef{Name: "Reason", Type: dcmd.String},
 		},
 		ArgSwitches: []*dcmd.ArgDef{
 			&dcmd.ArgDef{Switch: "d", Default: time.Duration(0), Name: "Duration", Type: &commands.DurationArg{}},
+			&dcmd.ArgDef{Switch: "shared", Name: "Share the ban with partner servers"},
 		},
 		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
 			config, target, err := MBaseCmd(parsed, parsed.Args[0].Int64())
@@ -148,7 +158,22 @@ var ModerationCommands = []*commands.YAGCommand{
 				return nil, err
 			}
 
-			return GenericCmdResp(MABanned, target, parsed.Switch("d").Value.(time.Duration), true, false), nil
+			resp := GenericCmdResp(MABanned, target, parsed.Switch("d").Value.(time.Duration), true, false)
+			if parsed.Switches["shared"].Value != nil && parsed.Switches["shared"].Value.(bool) {
+				parsed.GS.RLock()
+				guildName := parsed.GS.Guild.Name
+				parsed.GS.RUnlock()
+
+				numPartners, err := ShareBan(parsed.GS.ID, guildName, parsed.Msg.Author, reason, target)
+				if err != nil {
+					logrus.WithError(err).WithField("guild", parsed.GS.ID).Error("[moderation] failed sharing ban")
+					return resp + "\nFailed sharing the ban with partner servers", nil
+				}
+
+				resp += fmt.Sprintf("\nShared the ban with %d partner server(s)", numPartners)
+			}
+
+			return resp, nil
 		},
 	},
 	&commands.YAGCommand{
@@ -181,6 +206,13 @@ var ModerationCommands = []*commands.YAGCommand{
 			return GenericCmdResp(MAKick, target, 0, true, true), nil
 		},
 	},
+	massActionCommand(PunishmentBan),
+	massActionCommand(PunishmentKick),
+	cmdQuarantine,
+	cmdUnquarantine,
+	cmdLockdown,
+	cmdUnlock,
+	cmdSlowmode,
 	&commands.YAGCommand{
 		CustomEnabled: true,
 		CmdCategory:   commands.CategoryModeration,
@@ -262,67 +294,39 @@ var ModerationCommands = []*commands.YAGCommand{
 			return GenericCmdResp(MAUnmute, target, 0, false, true), nil
 		},
 	},
+	cmdReport,
+	cmdModStats,
+	cmdReports,
+	cmdClaimReport,
+	cmdResolveReport,
+	cmdDismissReport,
 	&commands.YAGCommand{
 		CustomEnabled: true,
-		Cooldown:      5,
 		CmdCategory:   commands.CategoryModeration,
-		Name:          "Report",
-		Description:   "Reports a member to the server's staff",
-		RequiredArgs:  2,
-		Arguments: []*dcmd.ArgDef{
-			&dcmd.ArgDef{Name: "User", Type: dcmd.UserID},
-			&dcmd.ArgDef{Name: "Reason", Type: dcmd.String},
-		},
-		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
-			config, _, err := MBaseCmd(parsed, 0)
-			if err != nil {
-				return nil, err
-			}
-
-			_, err = MBaseCmdSecond(parsed, "", true, 0, nil, config.ReportEnabled)
-			if err != nil {
-				return nil, err
-			}
-
-			target := parsed.Args[0].Int64()
-
-			logLink := CreateLogs(parsed.GS.ID, parsed.CS.ID, parsed.Msg.Author)
-
-			channelID := config.IntReportChannel()
-			if channelID == 0 {
-				return "No report channel set up", nil
-			}
-
-			reportBody := fmt.Sprintf("<@%d> Reported <@%d> in <#%d> For `%s`\nLast 100 messages from channel: <%s>", parsed.Msg.Author.ID, target, parsed.Msg.ChannelID, parsed.Args[1].Str(), logLink)
-
-			_, err = common.BotSession.ChannelMessageSend(channelID, common.EscapeSpecialMentions(reportBody))
-			if err != nil {
-				return nil, err
-			}
-
-			// don't bother sending confirmation if it's in the same channel
-			if channelID != parsed.Msg.ChannelID {
-				return "User reported to the proper authorities", nil
-			}
-			return nil, nil
-		},
-	},
-	&commands.YAGCommand{
-		CustomEnabled:   true,
-		CmdCategory:     commands.CategoryModeration,
-		Name:            "Clean",
-		Description:     "Delete the last number of messages from chat, optionally filtering by user, max age and regex.",
-		LongDescription: "Specify a regex with \"-r regex_here\" and max age with \"-ma 1h10m\"\nNote: Will only look in the last 1k messages",
-		Aliases:         []string{"clear", "cl"},
-		RequiredArgs:    1,
+		Name:          "Clean",
+		Description:   "Delete the last number of messages from chat, optionally filtering by user, max age, regex, content and more.",
+		LongDescription: "Specify a regex with \"-r regex_here\" and max age with \"-ma 1h10m\"\n" +
+			"Only delete messages from bots with -bots, with attachments with -attachments, containing links or invites with -links and with embeds with -embeds\n" +
+			"Only delete messages between 2 messages with \"-from message\" and \"-to message\" (ID's or links)\n" +
+			"-inv inverts the user, regex, bots, attachments, links and embeds filters\n" +
+			"Note: Up to 100 messages will only look in the last 1k messages, more than that is done in the background and looks through up to 10k messages",
+		Aliases:      []string{"clear", "cl"},
+		RequiredArgs: 1,
 		Arguments: []*dcmd.ArgDef{
-			&dcmd.ArgDef{Name: "Num", Type: &dcmd.IntArg{Min: 1, Max: 100}},
+			&dcmd.ArgDef{Name: "Num", Type: &dcmd.IntArg{Min: 1, Max: MaxCleanNum}},
 			&dcmd.ArgDef{Name: "User", Type: dcmd.UserID, Default: 0},
 		},
 		ArgSwitches: []*dcmd.ArgDef{
 			&dcmd.ArgDef{Switch: "r", Name: "Regex", Type: dcmd.String},
 			&dcmd.ArgDef{Switch: "ma", Default: time.Duration(0), Name: "Max age", Type: &commands.DurationArg{}},
 			&dcmd.ArgDef{Switch: "i", Name: "Regex case insensitive"},
+			&dcmd.ArgDef{Switch: "bots", Name: "Only bot messages"},
+			&dcmd.ArgDef{Switch: "attachments", Name: "Only messages with attachments"},
+			&dcmd.ArgDef{Switch: "links", Name: "Only messages containing links or invites"},
+			&dcmd.ArgDef{Switch: "embeds", Name: "Only messages with embeds"},
+			&dcmd.ArgDef{Switch: "from", Name: "Oldest message", Type: dcmd.String},
+			&dcmd.ArgDef{Switch: "to", Name: "Newest message", Type: dcmd.String},
+			&dcmd.ArgDef{Switch: "inv", Name: "Invert the filters"},
 		},
 		ArgumentCombos: [][]int{[]int{0}, []int{0, 1}, []int{1, 0}},
 		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
@@ -336,48 +340,79 @@ var ModerationCommands = []*commands.YAGCommand{
 				return nil, err
 			}
 
-			userFilter := parsed.Args[1].Int64()
-
-			num := parsed.Args[0].Int()
-			if userFilter == 0 || userFilter == parsed.Msg.Author.ID {
-				num++ // Automatically include our own message
+			switchEnabled := func(name string) bool {
+				return parsed.Switches[name].Value != nil && parsed.Switches[name].Value.(bool)
 			}
 
-			if num > 100 {
-				num = 100
-			}
-
-			if num < 1 {
-				if num < 0 {
-					return errors.New("Bot is having a stroke <https://www.youtube.com/watch?v=dQw4w9WgXcQ>"), nil
-				}
-				return errors.New("Can't delete nothing"), nil
+			filter := &CleanFilter{
+				User:        parsed.Args[1].Int64(),
+				Bots:        switchEnabled("bots"),
+				Attachments: switchEnabled("attachments"),
+				Links:       switchEnabled("links"),
+				Embeds:      switchEnabled("embeds"),
+				Inverse:     switchEnabled("inv"),
 			}
 
-			filtered := false
-
 			// Check if we should regex match this
-			re := ""
 			if parsed.Switches["r"].Value != nil {
-				filtered = true
-				re = parsed.Switches["r"].Str()
+				re := parsed.Switches["r"].Str()
 
 				// Add the case insensitive flag if needed
-				if parsed.Switches["i"].Value != nil && parsed.Switches["i"].Value.(bool) {
+				if switchEnabled("i") {
 					if !strings.HasPrefix(re, "(?i)") {
 						re = "(?i)" + re
 					}
 				}
+
+				filter.Regex, err = regexp.Compile(re)
+				if err != nil {
+					return nil, commands.NewPublicError("Invalid regex: ", err)
+				}
 			}
 
 			// Check if we have a max age
-			ma := parsed.Switches["ma"].Value.(time.Duration)
-			if ma != 0 {
-				filtered = true
+			filter.MaxAge = parsed.Switches["ma"].Value.(time.Duration)
+
+			if parsed.Switches["from"].Value != nil {
+				filter.From, err = parseCleanMessageID(parsed.Msg.ChannelID, parsed.Switches["from"].Str())
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if parsed.Switches["to"].Value != nil {
+				filter.To, err = parseCleanMessageID(parsed.Msg.ChannelID, parsed.Switches["to"].Str())
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if filter.From != 0 && filter.To != 0 && filter.From > filter.To {
+				filter.From, filter.To = filter.To, filter.From
+			}
+
+			if filter.Inverse && !filter.ContentFiltered() {
+				return "-inv needs at least one of the user, regex, bots, attachments, links or embeds filters", nil
+			}
+
+			num := parsed.Args[0].Int()
+			if filter.Match(dstate.MessageStateFromMessage(parsed.Msg), time.Now()) {
+				num++ // Automatically include our own message
+			}
+
+			if num < 1 {
+				if num < 0 {
+					return errors.New("Bot is having a stroke <https://www.youtube.com/watch?v=dQw4w9WgXcQ>"), nil
+				}
+				return errors.New("Can't delete nothing"), nil
+			}
+
+			if num > 100 {
+				return startBackgroundClean(parsed.GS.ID, parsed.Msg.ChannelID, parsed.Msg.ID, parsed.Msg.Author, filter, num)
 			}
 
 			limitFetch := num
-			if userFilter != 0 || filtered {
+			if filter.Filtered() {
 				limitFetch = num * 50 // Maybe just change to full fetch?
 			}
 
@@ -388,7 +423,10 @@ var ModerationCommands = []*commands.YAGCommand{
 			// Wait a second so the client dosen't gltich out
 			time.Sleep(time.Second)
 
-			numDeleted, err := AdvancedDeleteMessages(parsed.Msg.ChannelID, userFilter, re, ma, num, limitFetch)
+			numDeleted, err := AdvancedDeleteMessages(parsed.Msg.ChannelID, filter, num, limitFetch)
+			if numDeleted > 0 {
+				logClean(parsed.GS.ID, parsed.Msg.ChannelID, parsed.Msg.Author, numDeleted)
+			}
 
 			return dcmd.NewTemporaryResponse(time.Second*5, fmt.Sprintf("Deleted %d message(s)! :')", numDeleted), true), err
 		},
@@ -397,10 +435,10 @@ var ModerationCommands = []*commands.YAGCommand{
 		CustomEnabled: true,
 		CmdCategory:   commands.CategoryModeration,
 		Name:          "Reason",
-		Description:   "Add/Edit a modlog reason",
+		Description:   "Add/Edit the reason of a case, also accepts the message ID of a modlog entry",
 		RequiredArgs:  2,
 		Arguments: []*dcmd.ArgDef{
-			&dcmd.ArgDef{Name: "Message ID", Type: dcmd.Int},
+			&dcmd.ArgDef{Name: "Case", Type: dcmd.Int},
 			&dcmd.ArgDef{Name: "Reason", Type: dcmd.String},
 		},
 		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
@@ -414,11 +452,44 @@ var ModerationCommands = []*commands.YAGCommand{
 				return nil, err
 			}
 
+			num := parsed.Args[0].Int64()
+			if num < minModlogMessageID {
+				c, err := FindCase(parsed.GS.ID, num)
+				if err != nil {
+					return nil, err
+				}
+
+				if c == nil {
+					return "Couldn't find that case", nil
+				}
+
+				err = UpdateCaseReason(c, parsed.Msg.Author, parsed.Args[1].Str())
+				if err != nil {
+					return nil, err
+				}
+
+				return "👌", nil
+			}
+
+			// a modlog message id, if there's a case for it then update that instead
+			var c ModerationCase
+			err = common.GORM.Where("guild_id = ? AND modlog_message_id = ?", parsed.GS.ID, num).First(&c).Error
+			if err == nil {
+				err = UpdateCaseReason(&c, parsed.Msg.Author, parsed.Args[1].Str())
+				if err != nil {
+					return nil, err
+				}
+
+				return "👌", nil
+			} else if err != gorm.ErrRecordNotFound {
+				return nil, err
+			}
+
 			if config.ActionChannel == "" {
 				return "No mod log channel set up", nil
 			}
 
-			msg, err := common.BotSession.ChannelMessage(config.IntActionChannel(), parsed.Args[0].Int64())
+			msg, err := common.BotSession.ChannelMessage(config.IntActionChannel(), num)
 			if err != nil {
 				return nil, err
 			}
@@ -441,6 +512,84 @@ var ModerationCommands = []*commands.YAGCommand{
 			return "👌", nil
 		},
 	},
+	&commands.YAGCommand{
+		CustomEnabled: true,
+		CmdCategory:   commands.CategoryModeration,
+		Name:          "Case",
+		Description:   "Shows a moderation case",
+		RequiredArgs:  1,
+		Arguments: []*dcmd.ArgDef{
+			&dcmd.ArgDef{Name: "Case", Type: dcmd.Int},
+		},
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			_, err := MBaseCmdSecond(parsed, "", true, discordgo.PermissionKickMembers, nil, true)
+			if err != nil {
+				return nil, err
+			}
+
+			c, err := FindCase(parsed.GS.ID, parsed.Args[0].Int64())
+			if err != nil {
+				return nil, err
+			}
+
+			if c == nil {
+				return "Couldn't find that case", nil
+			}
+
+			return CaseEmbed(c), nil
+		},
+	},
+	&commands.YAGCommand{
+		CustomEnabled: true,
+		CmdCategory:   commands.CategoryModeration,
+		Name:          "Cases",
+		Description:   "Lists the moderation cases of a user, newest first",
+		RequiredArgs:  1,
+		Arguments: []*dcmd.ArgDef{
+			&dcmd.ArgDef{Name: "User", Type: dcmd.UserID},
+			&dcmd.ArgDef{Name: "Page", Type: &dcmd.IntArg{Min: 1, Max: 10000}, Default: 1},
+		},
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			_, err := MBaseCmdSecond(parsed, "", true, discordgo.PermissionKickMembers, nil, true)
+			if err != nil {
+				return nil, err
+			}
+
+			userID := parsed.Args[0].Int64()
+			page := parsed.Args[1].Int()
+
+			var total int
+			err = common.GORM.Model(&ModerationCase{}).Where("guild_id = ? AND user_id = ?", parsed.GS.ID, userID).Count(&total).Error
+			if err != nil {
+				return nil, err
+			}
+
+			if total < 1 {
+				return "This user has no cases", nil
+			}
+
+			var result []*ModerationCase
+			err = common.GORM.Where("guild_id = ? AND user_id = ?", parsed.GS.ID, userID).Order("case_number desc").
+				Offset((page - 1) * casesPerPage).Limit(casesPerPage).Find(&result).Error
+			if err != nil {
+				return nil, err
+			}
+
+			maxPage := (total + casesPerPage - 1) / casesPerPage
+			out := fmt.Sprintf("Cases of %d (page %d/%d, %d total):\n", userID, page, maxPage, total)
+			for _, c := range result {
+				action := c.ModlogAction()
+				moderator := c.AuthorUsernameDiscrim
+				if c.AuthorID == 0 {
+					moderator = "Unknown"
+				}
+
+				out += fmt.Sprintf("#%d: `%20s` %s%s by **%s** - **%s**\n", c.CaseNumber, c.CreatedAt.Format(time.RFC822), action.Emoji, action.Prefix, moderator, common.CutStringShort(c.Reason, 100))
+			}
+
+			return out, nil
+		},
+	},
 	&commands.YAGCommand{
 		CustomEnabled: true,
 		CmdCategory:   commands.CategoryModeration,
@@ -737,17 +886,7 @@ var ModerationCommands = []*commands.YAGCommand{
 	},
 }
 
-func AdvancedDeleteMessages(channelID int64, filterUser int64, regex string, maxAge time.Duration, deleteNum, fetchNum int) (int, error) {
-	var compiledRegex *regexp.Regexp
-	if regex != "" {
-		// Start by compiling the regex
-		var err error
-		compiledRegex, err = regexp.Compile(regex)
-		if err != nil {
-			return 0, err
-		}
-	}
-
+func AdvancedDeleteMessages(channelID int64, filter *CleanFilter, deleteNum, fetchNum int) (int, error) {
 	msgs, err := bot.GetMessages(channelID, fetchNum, false)
 	if err != nil {
 		return 0, err
@@ -756,24 +895,12 @@ func AdvancedDeleteMessages(channelID int64, filterUser int64, regex string, max
 	toDelete := make([]int64, 0)
 	now := time.Now()
 	for i := len(msgs) - 1; i >= 0; i-- {
-		if filterUser != 0 && msgs[i].Author.ID != filterUser {
+		// Can only bulk delete messages up to 2 weeks
+		if now.Sub(msgs[i].ParsedCreated) > maxBulkDeleteAge {
 			continue
 		}
 
-		// Can only bulk delete messages up to 2 weeks (but add 1 minute buffer account for time sync issues and other smallies)
-		if now.Sub(msgs[i].ParsedCreated) > (time.Hour*24*14)-time.Minute {
-			continue
-		}
-
-		// Check regex
-		if compiledRegex != nil {
-			if !compiledRegex.MatchString(msgs[i].Content) {
-				continue
-			}
-		}
-
-		// Check max age
-		if maxAge != 0 && now.Sub(msgs[i].ParsedCreated) > maxAge {
+		if !filter.Match(msgs[i], now) {
 			continue
 		}
 
@@ -784,18 +911,7 @@ func AdvancedDeleteMessages(channelID int64, filterUser int64, regex string, max
 		}
 	}
 
-	if len(toDelete) < 1 {
-		return 0, nil
-	}
-
-	if len(toDelete) < 1 {
-		return 0, nil
-	} else if len(toDelete) == 1 {
-		err = common.BotSession.ChannelMessageDelete(channelID, toDelete[0])
-	} else {
-		err = common.BotSession.ChannelMessagesBulkDelete(channelID, toDelete)
-	}
-
+	err = deleteMessageIDs(channelID, toDelete)
 	return len(toDelete), err
 }
 
@@ -0,0 +1,142 @@
+package moderation
+
+import (
+	"fmt"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/bot"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type EscalationAction int
+
+const (
+	EscalationMute EscalationAction = iota
+	EscalationKick
+	EscalationBan
+)
+
+func (e EscalationAction) String() string {
+	switch e {
+	case EscalationMute:
+		return "Mute"
+	case EscalationKick:
+		return "Kick"
+	case EscalationBan:
+		return "Ban"
+	}
+
+	return "Unknown"
+}
+
+const MaxEscalationSteps = 10
+
+// WarningEscalationStep is a single step of the warning escalation ladder, when a user reaches the number of
+// warnings within the time window the action is executed
+type WarningEscalationStep struct {
+	common.SmallModel
+	GuildID int64 `gorm:"index"`
+
+	Warnings   int
+	WithinDays int // 0 for all time
+
+	Action EscalationAction
+
+	// in minutes, used for mutes and bans, 0 is a permanent ban
+	Duration int
+}
+
+func (s *WarningEscalationStep) TableName() string {
+	return "moderation_warning_escalation_steps"
+}
+
+func (s *WarningEscalationStep) String() string {
+	window := "in total"
+	if s.WithinDays > 0 {
+		window = fmt.Sprintf("within %d day(s)", s.WithinDays)
+	}
+
+	str := fmt.Sprintf("%d warnings %s: %s", s.Warnings, window, s.Action)
+	if s.Action == EscalationMute || (s.Action == EscalationBan && s.Duration > 0) {
+		str += " for " + common.HumanizeDuration(common.DurationPrecisionMinutes, time.Duration(s.Duration)*time.Minute)
+	}
+
+	return str
+}
+
+// GetEscalationSteps returns the escalation steps of the guild, ordered by the number of warnings
+func GetEscalationSteps(guildID int64) ([]*WarningEscalationStep, error) {
+	var steps []*WarningEscalationStep
+	err := common.GORM.Where("guild_id = ?", guildID).Order("warnings asc, id asc").Find(&steps).Error
+	return steps, err
+}
+
+// escalateWarnings checks the warnings of the user against the escalation ladder, executing the most severe
+// step that was reached with the latest warning
+func escalateWarnings(config *Config, guildID, channelID int64, target *discordgo.User) error {
+	if !config.WarnEscalationEnabled {
+		return nil
+	}
+
+	steps, err := GetEscalationSteps(guildID)
+	if err != nil || len(steps) < 1 {
+		return err
+	}
+
+	var reached *WarningEscalationStep
+	for _, step := range steps {
+		query := common.GORM.Model(&WarningModel{}).Where("guild_id = ? AND user_id = ?", guildID, discordgo.StrID(target.ID))
+		if step.WithinDays > 0 {
+			query = query.Where("created_at > ?", time.Now().Add(-time.Hour*24*time.Duration(step.WithinDays)))
+		}
+
+		var count int
+		err = query.Count(&count).Error
+		if err != nil {
+			return err
+		}
+
+		// only escalate when the threshold is reached, not on every warning after it
+		if count != step.Warnings {
+			continue
+		}
+
+		if reached == nil || step.Action > reached.Action || (step.Action == reached.Action && step.Duration > reached.Duration) {
+			reached = step
+		}
+	}
+
+	if reached == nil {
+		return nil
+	}
+
+	// the caller's config may have the modlog channel cleared (automod batching its modlog entries),
+	// so load the guild's config again and only keep the automod trigger from the caller's
+	fresh, err := GetConfig(guildID)
+	if err != nil {
+		return err
+	}
+
+	escalationConfig := *fresh
+	escalationConfig.AutomodTrigger = config.AutomodTrigger
+
+	reason := "Warning escalation: " + reached.String()
+	logrus.WithField("guild", guildID).WithField("user", target.ID).Info("[moderation] escalating warnings: ", reached.String())
+
+	switch reached.Action {
+	case EscalationMute:
+		member, err := bot.GetMember(guildID, target.ID)
+		if err != nil || member == nil {
+			return err
+		}
+
+		return MuteUnmuteUser(&escalationConfig, true, guildID, channelID, common.BotUser, reason, member, reached.Duration)
+	case EscalationKick:
+		return KickUser(&escalationConfig, guildID, channelID, common.BotUser, reason, target)
+	case EscalationBan:
+		return BanUserWithDuration(&escalationConfig, guildID, channelID, common.BotUser, reason, target, time.Duration(reached.Duration)*time.Minute)
+	}
+
+	return nil
+}
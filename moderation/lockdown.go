@@ -0,0 +1,526 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jonas747/dcmd"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/dstate"
+	"github.com/jonas747/yagpdb/commands"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/common/scheduledevents2"
+	seventsmodels "github.com/jonas747/yagpdb/common/scheduledevents2/models"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	MALockdown = ModlogAction{Prefix: "Locked down", Emoji: "🔒", Color: 0xd64848}
+	MAUnlock   = ModlogAction{Prefix: "Unlocked", Emoji: "🔓", Color: 0x62c65f}
+	MASlowmode = ModlogAction{Prefix: "Set slowmode in", Emoji: "🐌", Color: 0x57728e}
+)
+
+// The permissions denied for @everyone during a lockdown
+const LockdownDeniedPerms = discordgo.PermissionSendMessages
+
+// LockedChannel is a channel currently in lockdown, it holds the @everyone overwrite from before the lockdown
+// so that it can be restored exactly as it was
+type LockedChannel struct {
+	common.SmallModel
+	GuildID   int64 `gorm:"index"`
+	ChannelID int64 `gorm:"unique_index"`
+
+	HadOverwrite bool
+	PrevAllow    int
+	PrevDeny     int
+
+	AuthorID int64
+	Reason   string
+}
+
+func (l *LockedChannel) TableName() string {
+	return "moderation_locked_channels"
+}
+
+type ScheduledUnlockData struct {
+	// IDs of the LockedChannel entries, so that the channels are not unlocked if they were unlocked and locked again in the meantime
+	Locks []int64 `json:"locks"`
+}
+
+type ScheduledSlowmodeRevertData struct {
+	ChannelID int64 `json:"channel_id"`
+	Seconds   int   `json:"seconds"`
+}
+
+var channelIDRegex = regexp.MustCompile(`\d{15,20}`)
+
+var lockdownTargetHelp = "Target can be `all` for all text channels, `category` for the channels in the current category, " +
+	"or a comma separated list of channels, defaults to the current channel."
+
+var cmdLockdown = &commands.YAGCommand{
+	CustomEnabled:   true,
+	CmdCategory:     commands.CategoryModeration,
+	Name:            "Lockdown",
+	Description:     "Prevents @everyone from sending messages in the channels, optionally for a duration",
+	LongDescription: lockdownTargetHelp + "\nThe previous permissions are restored when the channels are unlocked.",
+	Arguments: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Name: "Target", Type: dcmd.String},
+		&dcmd.ArgDef{Name: "Duration", Default: time.Duration(0), Type: &commands.DurationArg{}},
+		&dcmd.ArgDef{Name: "Reason", Type: dcmd.String},
+	},
+	ArgumentCombos: [][]int{[]int{0, 1, 2}, []int{0, 2, 1}, []int{0, 1}, []int{0, 2}, []int{0}, []int{}},
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		config, _, err := MBaseCmd(parsed, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		reason, err := MBaseCmdSecond(parsed, SafeArgString(parsed, 2), true, discordgo.PermissionManageChannels, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		channels, err := lockdownTargetChannels(parsed)
+		if err != nil {
+			return nil, err
+		}
+
+		duration := parsed.Args[1].Value.(time.Duration)
+		locked, err := LockChannels(config, parsed.GS.ID, parsed.Msg.Author, channels, reason, duration)
+		if err != nil {
+			if len(locked) < 1 {
+				return nil, err
+			}
+
+			logrus.WithError(err).WithField("guild", parsed.GS.ID).Error("[moderation] failed locking channels")
+			return fmt.Sprintf("%s%s %s%s (an error occurred, some channels may not have been locked or scheduled for unlocking)", MALockdown.Emoji, MALockdown.Prefix, channelMentions(locked), lockdownDurationStr(duration)), nil
+		}
+
+		if len(locked) < 1 {
+			return "Those channels are already locked", nil
+		}
+
+		return fmt.Sprintf("%s%s %s%s", MALockdown.Emoji, MALockdown.Prefix, channelMentions(locked), lockdownDurationStr(duration)), nil
+	},
+}
+
+var cmdUnlock = &commands.YAGCommand{
+	CustomEnabled:   true,
+	CmdCategory:     commands.CategoryModeration,
+	Name:            "Unlock",
+	Description:     "Lifts a lockdown, restoring the previous permissions",
+	LongDescription: lockdownTargetHelp,
+	Arguments: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Name: "Target", Type: dcmd.String},
+		&dcmd.ArgDef{Name: "Reason", Type: dcmd.String},
+	},
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		config, _, err := MBaseCmd(parsed, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		reason, err := MBaseCmdSecond(parsed, SafeArgString(parsed, 1), true, discordgo.PermissionManageChannels, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		channels, err := lockdownTargetChannels(parsed)
+		if err != nil {
+			return nil, err
+		}
+
+		var locks []*LockedChannel
+		err = common.GORM.Where("guild_id = ? AND channel_id IN (?)", parsed.GS.ID, channelIDs(channels)).Find(&locks).Error
+		if err != nil {
+			return nil, err
+		}
+
+		unlocked, err := UnlockChannels(config, parsed.GS.ID, parsed.Msg.Author, locks, reason)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(unlocked) < 1 {
+			return "None of those channels are locked", nil
+		}
+
+		return fmt.Sprintf("%s%s %s", MAUnlock.Emoji, MAUnlock.Prefix, channelMentions(unlocked)), nil
+	},
+}
+
+var cmdSlowmode = &commands.YAGCommand{
+	CustomEnabled: true,
+	CmdCategory:   commands.CategoryModeration,
+	Name:          "Slowmode",
+	Description:   "Sets the slowmode of a channel, optionally reverting it to the previous value after the duration",
+	Arguments: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Name: "Channel", Type: dcmd.Channel},
+		&dcmd.ArgDef{Name: "Seconds", Type: &dcmd.IntArg{Min: 0, Max: 21600}},
+		&dcmd.ArgDef{Name: "Duration", Default: time.Duration(0), Type: &commands.DurationArg{}},
+	},
+	RequiredArgs: 2,
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		config, _, err := MBaseCmd(parsed, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = MBaseCmdSecond(parsed, "", true, discordgo.PermissionManageChannels, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		cs := parsed.Args[0].Value.(*dstate.ChannelState)
+		seconds := parsed.Args[1].Int()
+		duration := parsed.Args[2].Value.(time.Duration)
+
+		err = SetSlowmode(config, parsed.GS.ID, cs.ID, parsed.Msg.Author, seconds, duration)
+		if err != nil {
+			return nil, err
+		}
+
+		return fmt.Sprintf("%s%s <#%d>: %d seconds%s", MASlowmode.Emoji, MASlowmode.Prefix, cs.ID, seconds, lockdownDurationStr(duration)), nil
+	},
+}
+
+// lockdownTargetChannels returns the text channels targeted by the first argument of the lockdown and unlock commands
+func lockdownTargetChannels(parsed *dcmd.Data) ([]*discordgo.Channel, error) {
+	target := strings.ToLower(strings.TrimSpace(SafeArgString(parsed, 0)))
+
+	gs := parsed.GS
+	gs.RLock()
+	channels := make([]*discordgo.Channel, 0, len(gs.Channels))
+	for _, v := range gs.Channels {
+		channels = append(channels, v.DGoCopy())
+	}
+	gs.RUnlock()
+
+	var current *discordgo.Channel
+	for _, v := range channels {
+		if v.ID == parsed.CS.ID {
+			current = v
+			break
+		}
+	}
+
+	var result []*discordgo.Channel
+	switch target {
+	case "", "here":
+		if current != nil {
+			result = append(result, current)
+		}
+	case "all", "category":
+		if target == "category" && (current == nil || current.ParentID == 0) {
+			return nil, commands.NewPublicError("This channel is not in a category")
+		}
+
+		for _, v := range channels {
+			if v.Type != discordgo.ChannelTypeGuildText {
+				continue
+			}
+
+			if target == "category" && v.ParentID != current.ParentID {
+				continue
+			}
+
+			result = append(result, v)
+		}
+	default:
+	OUTER:
+		for _, v := range channelIDRegex.FindAllString(target, -1) {
+			id, _ := strconv.ParseInt(v, 10, 64)
+			for _, c := range channels {
+				if c.ID == id && c.Type == discordgo.ChannelTypeGuildText {
+					result = append(result, c)
+					continue OUTER
+				}
+			}
+
+			return nil, commands.NewPublicErrorF("Unknown text channel: `%s`", v)
+		}
+
+		if len(result) < 1 {
+			return nil, commands.NewPublicError(lockdownTargetHelp)
+		}
+	}
+
+	return result, nil
+}
+
+// LockChannels denies LockdownDeniedPerms for @everyone in the channels, remembering the previous overwrite,
+// if duration is above 0 the channels are automatically unlocked after it. Returns the channels that were locked
+func LockChannels(config *Config, guildID int64, author *discordgo.User, channels []*discordgo.Channel, reason string, duration time.Duration) ([]int64, error) {
+	var lockIDs []int64
+	var locked []int64
+
+	// if locking one of the channels fails, the ones already locked still get the automatic unlock and modlog entry
+	var lockErr error
+	for _, c := range channels {
+		var count int
+		err := common.GORM.Model(&LockedChannel{}).Where("channel_id = ?", c.ID).Count(&count).Error
+		if err != nil {
+			lockErr = err
+			break
+		}
+
+		if count > 0 {
+			// already locked, the original overwrite has already been stored
+			continue
+		}
+
+		lock := &LockedChannel{
+			GuildID:   guildID,
+			ChannelID: c.ID,
+			AuthorID:  author.ID,
+			Reason:    reason,
+		}
+
+		for _, v := range c.PermissionOverwrites {
+			if v.Type == "role" && v.ID == guildID {
+				lock.HadOverwrite = true
+				lock.PrevAllow = v.Allow
+				lock.PrevDeny = v.Deny
+				break
+			}
+		}
+
+		// save it first so that the permissions can always be restored
+		err = common.GORM.Create(lock).Error
+		if err != nil {
+			lockErr = err
+			break
+		}
+
+		// the @everyone role has the same id as the guild
+		err = denyRolePermsInChannel(c, guildID, LockdownDeniedPerms)
+		if err != nil {
+			common.GORM.Delete(lock)
+			lockErr = err
+			break
+		}
+
+		lockIDs = append(lockIDs, lock.ID)
+		locked = append(locked, c.ID)
+	}
+
+	if len(locked) < 1 {
+		return locked, lockErr
+	}
+
+	if duration > 0 {
+		err := scheduledevents2.ScheduleEvent("moderation_unlock_channels", guildID, time.Now().Add(duration), &ScheduledUnlockData{
+			Locks: lockIDs,
+		})
+		if err != nil {
+			return locked, common.ErrWithCaller(err)
+		}
+	}
+
+	err := createChannelModlogEmbed(config, author, MALockdown, channelMentions(locked)+lockdownDurationStr(duration), reason)
+	if lockErr != nil {
+		return locked, lockErr
+	}
+
+	return locked, err
+}
+
+// UnlockChannels restores the overwrites from before the lockdown, returning the channels that were unlocked
+func UnlockChannels(config *Config, guildID int64, author *discordgo.User, locks []*LockedChannel, reason string) ([]int64, error) {
+	var unlocked []int64
+	for _, lock := range locks {
+		var err error
+		if lock.HadOverwrite {
+			err = common.BotSession.ChannelPermissionSet(lock.ChannelID, guildID, "role", lock.PrevAllow, lock.PrevDeny)
+		} else {
+			err = common.BotSession.ChannelPermissionDelete(lock.ChannelID, guildID)
+		}
+
+		if err != nil && !common.IsDiscordErr(err, discordgo.ErrCodeUnknownChannel) {
+			return unlocked, err
+		}
+
+		err = common.GORM.Delete(lock).Error
+		if err != nil {
+			return unlocked, err
+		}
+
+		unlocked = append(unlocked, lock.ChannelID)
+	}
+
+	if len(unlocked) < 1 {
+		return unlocked, nil
+	}
+
+	return unlocked, createChannelModlogEmbed(config, author, MAUnlock, channelMentions(unlocked), reason)
+}
+
+// SetSlowmode sets the slowmode of the channel, reverting it to the previous value after duration if it's above 0
+func SetSlowmode(config *Config, guildID, channelID int64, author *discordgo.User, seconds int, duration time.Duration) error {
+	previous, err := getChannelSlowmode(channelID)
+	if err != nil {
+		return err
+	}
+
+	// if a revert is already pending, then keep reverting to the original value
+	pending, err := seventsmodels.ScheduledEvents(qm.Where("event_name='moderation_revert_slowmode' AND guild_id = ? AND (data->>'channel_id')::bigint = ? AND processed = false", guildID, channelID)).All(context.Background(), common.PQ)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range pending {
+		var data ScheduledSlowmodeRevertData
+		if json.Unmarshal(v.Data, &data) == nil {
+			previous = data.Seconds
+		}
+	}
+
+	if len(pending) > 0 {
+		_, err = pending.DeleteAll(context.Background(), common.PQ)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = setChannelSlowmode(channelID, seconds)
+	if err != nil {
+		return err
+	}
+
+	if duration > 0 && previous != seconds {
+		err = scheduledevents2.ScheduleEvent("moderation_revert_slowmode", guildID, time.Now().Add(duration), &ScheduledSlowmodeRevertData{
+			ChannelID: channelID,
+			Seconds:   previous,
+		})
+		if err != nil {
+			return common.ErrWithCaller(err)
+		}
+	}
+
+	desc := fmt.Sprintf("<#%d>: %d seconds%s", channelID, seconds, lockdownDurationStr(duration))
+	return createChannelModlogEmbed(config, author, MASlowmode, desc, "")
+}
+
+type channelSlowmode struct {
+	RateLimitPerUser int `json:"rate_limit_per_user"`
+}
+
+// getChannelSlowmode and setChannelSlowmode does raw requests since we need to be able to set it to 0
+func getChannelSlowmode(channelID int64) (int, error) {
+	body, err := common.BotSession.RequestWithBucketID("GET", discordgo.EndpointChannel(channelID), nil, discordgo.EndpointChannel(channelID))
+	if err != nil {
+		return 0, err
+	}
+
+	var dst channelSlowmode
+	err = json.Unmarshal(body, &dst)
+	return dst.RateLimitPerUser, err
+}
+
+func setChannelSlowmode(channelID int64, seconds int) error {
+	_, err := common.BotSession.RequestWithBucketID("PATCH", discordgo.EndpointChannel(channelID), &channelSlowmode{RateLimitPerUser: seconds}, discordgo.EndpointChannel(channelID))
+	return err
+}
+
+func handleScheduledUnlock(evt *seventsmodels.ScheduledEvent, data interface{}) (retry bool, err error) {
+	unlockData := data.(*ScheduledUnlockData)
+	if len(unlockData.Locks) < 1 {
+		return false, nil
+	}
+
+	var locks []*LockedChannel
+	err = common.GORM.Where("guild_id = ? AND id IN (?)", evt.GuildID, unlockData.Locks).Find(&locks).Error
+	if err != nil {
+		return true, err
+	}
+
+	config, err := GetConfig(evt.GuildID)
+	if err != nil {
+		return true, err
+	}
+
+	_, err = UnlockChannels(config, evt.GuildID, common.BotUser, locks, "Lockdown duration expired")
+	return scheduledevents2.CheckDiscordErrRetry(err), err
+}
+
+func handleScheduledSlowmodeRevert(evt *seventsmodels.ScheduledEvent, data interface{}) (retry bool, err error) {
+	revertData := data.(*ScheduledSlowmodeRevertData)
+
+	err = setChannelSlowmode(revertData.ChannelID, revertData.Seconds)
+	if err != nil {
+		if common.IsDiscordErr(err, discordgo.ErrCodeUnknownChannel) {
+			return false, nil
+		}
+
+		return scheduledevents2.CheckDiscordErrRetry(err), err
+	}
+
+	config, err := GetConfig(evt.GuildID)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", evt.GuildID).Error("failed retrieving config for slowmode revert")
+		return false, nil
+	}
+
+	desc := fmt.Sprintf("<#%d>: %d seconds", revertData.ChannelID, revertData.Seconds)
+	err = createChannelModlogEmbed(config, common.BotUser, MASlowmode, desc, "Slowmode duration expired")
+	if err != nil {
+		logrus.WithError(err).WithField("guild", evt.GuildID).Error("failed creating slowmode revert modlog entry")
+	}
+
+	return false, nil
+}
+
+// createChannelModlogEmbed posts a modlog entry for a action that targets channels instead of users
+func createChannelModlogEmbed(config *Config, author *discordgo.User, action ModlogAction, target, reason string) error {
+	channelID := config.IntActionChannel()
+	if channelID == 0 {
+		return nil
+	}
+
+	if reason == "" {
+		reason = "(no reason specified)"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    fmt.Sprintf("%s#%s (ID %d)", author.Username, author.Discriminator, author.ID),
+			IconURL: discordgo.EndpointUserAvatar(author.ID, author.Avatar),
+		},
+		Color:       action.Color,
+		Description: fmt.Sprintf("**%s%s** %s\n📄**Reason:** %s", action.Emoji, action.Prefix, target, reason),
+	}
+
+	_, err := common.BotSession.ChannelMessageSendEmbed(channelID, embed)
+	return err
+}
+
+func channelMentions(channels []int64) string {
+	mentions := make([]string, 0, len(channels))
+	for _, v := range channels {
+		mentions = append(mentions, "<#"+discordgo.StrID(v)+">")
+	}
+
+	return common.CutStringShort(strings.Join(mentions, ", "), 1500)
+}
+
+func channelIDs(channels []*discordgo.Channel) []int64 {
+	ids := make([]int64, 0, len(channels))
+	for _, v := range channels {
+		ids = append(ids, v.ID)
+	}
+
+	return ids
+}
+
+func lockdownDurationStr(duration time.Duration) string {
+	if duration <= 0 {
+		return ""
+	}
+
+	return " for `" + common.HumanizeDuration(common.DurationPrecisionMinutes, duration) + "`"
+}
@@ -0,0 +1,334 @@
+package moderation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jonas747/dcmd"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/dstate"
+	"github.com/jonas747/yagpdb/bot"
+	"github.com/jonas747/yagpdb/commands"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/mediocregopher/radix"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Max number of users that can be affected by a single mass action
+	MaxMassActionUsers = 250
+
+	// How long the preview is valid for
+	massActionConfirmExpiry = 300
+)
+
+func RedisKeyMassAction(guildID, authorID int64) string {
+	return "moderation_mass_action:" + discordgo.StrID(guildID) + ":" + discordgo.StrID(authorID)
+}
+
+func RedisKeyMassActionLock(guildID int64) string {
+	return "moderation_mass_action_lock:" + discordgo.StrID(guildID)
+}
+
+// pendingMassAction is the result of a preview, waiting to be confirmed
+type pendingMassAction struct {
+	Punishment Punishment `json:"punishment"`
+	Users      []int64    `json:"users"`
+	Reason     string     `json:"reason"`
+}
+
+var massActionUserIDRegex = regexp.MustCompile(`\d{15,20}`)
+
+func massActionCommand(p Punishment) *commands.YAGCommand {
+	name := "MassBan"
+	action := MABanned
+	if p == PunishmentKick {
+		name = "MassKick"
+		action = MAKick
+	}
+
+	return &commands.YAGCommand{
+		CustomEnabled: true,
+		CmdCategory:   commands.CategoryModeration,
+		Name:          name,
+		Description:   fmt.Sprintf("%s the specified users, or users matching the filters. Shows a preview first which has to be confirmed with -confirm", action.Prefix),
+		LongDescription: "Specify users by ID or mention, and/or filter members with:\n" +
+			"-joined 10m - joined within the last 10 minutes\n" +
+			"-age 1d - account created within the last day\n" +
+			"-r regex - username matches the regex\n" +
+			"Then run the command with just -confirm to execute it.",
+		Arguments: []*dcmd.ArgDef{
+			&dcmd.ArgDef{Name: "Users", Type: dcmd.String},
+		},
+		ArgSwitches: []*dcmd.ArgDef{
+			&dcmd.ArgDef{Switch: "joined", Name: "Joined within", Type: &commands.DurationArg{}},
+			&dcmd.ArgDef{Switch: "age", Name: "Account younger than", Type: &commands.DurationArg{}},
+			&dcmd.ArgDef{Switch: "r", Name: "Username regex", Type: dcmd.String},
+			&dcmd.ArgDef{Switch: "reason", Name: "Reason", Type: dcmd.String},
+			&dcmd.ArgDef{Switch: "confirm", Name: "Confirm the previewed action"},
+		},
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			config, _, err := MBaseCmd(parsed, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			reason := ""
+			if parsed.Switches["reason"].Value != nil {
+				reason = parsed.Switches["reason"].Str()
+			}
+
+			if p == PunishmentKick {
+				reason, err = MBaseCmdSecond(parsed, reason, config.KickReasonOptional, discordgo.PermissionKickMembers, config.KickCmdRoles, config.KickEnabled)
+			} else {
+				reason, err = MBaseCmdSecond(parsed, reason, config.BanReasonOptional, discordgo.PermissionBanMembers, config.BanCmdRoles, config.BanEnabled)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if parsed.Switches["confirm"].Value != nil && parsed.Switches["confirm"].Value.(bool) {
+				return confirmMassAction(config, parsed, p)
+			}
+
+			return previewMassAction(parsed, p, reason)
+		},
+	}
+}
+
+// previewMassAction finds the users that will be affected and stores them so that they can be confirmed
+func previewMassAction(parsed *dcmd.Data, p Punishment, reason string) (interface{}, error) {
+	var joinedWithin, youngerThan time.Duration
+	if v := parsed.Switches["joined"].Value; v != nil {
+		joinedWithin = v.(time.Duration)
+	}
+	if v := parsed.Switches["age"].Value; v != nil {
+		youngerThan = v.(time.Duration)
+	}
+
+	var usernameRegex *regexp.Regexp
+	if parsed.Switches["r"].Value != nil {
+		var err error
+		usernameRegex, err = regexp.Compile(parsed.Switches["r"].Str())
+		if err != nil {
+			return "Invalid regex: " + err.Error(), nil
+		}
+	}
+
+	explicitIDs := massActionUserIDRegex.FindAllString(SafeArgString(parsed, 0), -1)
+	hasFilter := joinedWithin > 0 || youngerThan > 0 || usernameRegex != nil
+	if len(explicitIDs) < 1 && !hasFilter {
+		return "Specify atleast one user or filter, see the help for this command for more info", nil
+	}
+
+	author := commands.ContextMS(parsed.Context())
+	gs := parsed.GS
+	now := time.Now()
+
+	targets := make([]int64, 0)
+	skipped := 0
+
+	// canTarget needs to be called with the guild state locked
+	canTarget := func(ms *dstate.MemberState) bool {
+		if ms.ID == author.ID || ms.ID == common.BotUser.ID {
+			return false
+		}
+
+		return bot.IsMemberAbove(gs, author, ms)
+	}
+
+	gs.RLock()
+	for _, v := range explicitIDs {
+		id, _ := strconv.ParseInt(v, 10, 64)
+		if common.ContainsInt64Slice(targets, id) {
+			continue
+		}
+
+		if ms := gs.Member(false, id); ms != nil && !canTarget(ms) {
+			skipped++
+			continue
+		}
+
+		targets = append(targets, id)
+	}
+
+	if hasFilter {
+		for _, ms := range gs.Members {
+			if !ms.MemberSet || common.ContainsInt64Slice(targets, ms.ID) {
+				continue
+			}
+
+			if joinedWithin > 0 && now.Sub(ms.JoinedAt) > joinedWithin {
+				continue
+			}
+
+			if youngerThan > 0 && now.Sub(bot.SnowflakeToTime(ms.ID)) > youngerThan {
+				continue
+			}
+
+			if usernameRegex != nil && !usernameRegex.MatchString(ms.Username) {
+				continue
+			}
+
+			if !canTarget(ms) {
+				skipped++
+				continue
+			}
+
+			targets = append(targets, ms.ID)
+		}
+	}
+	gs.RUnlock()
+
+	if len(targets) < 1 {
+		return fmt.Sprintf("No users matched (%d skipped because they're ranked higher than you)", skipped), nil
+	}
+
+	if len(targets) > MaxMassActionUsers {
+		return fmt.Sprintf("Too many users matched (%d), the max is %d, narrow down the filters", len(targets), MaxMassActionUsers), nil
+	}
+
+	serialized, err := json.Marshal(&pendingMassAction{
+		Punishment: p,
+		Users:      targets,
+		Reason:     reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = common.RedisPool.Do(radix.Cmd(nil, "SET", RedisKeyMassAction(gs.ID, author.ID), string(serialized), "EX", strconv.Itoa(massActionConfirmExpiry)))
+	if err != nil {
+		return nil, err
+	}
+
+	verb := "banned"
+	if p == PunishmentKick {
+		verb = "kicked"
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("The following **%d** users will be %s with the reason `%s`:\n", len(targets), verb, reason))
+	for i, v := range targets {
+		if i >= 40 {
+			out.WriteString(fmt.Sprintf("...and %d more\n", len(targets)-i))
+			break
+		}
+
+		out.WriteString(massActionUserString(gs, v) + "\n")
+	}
+
+	if skipped > 0 {
+		out.WriteString(fmt.Sprintf("(%d skipped because they're ranked higher than you)\n", skipped))
+	}
+
+	out.WriteString(fmt.Sprintf("\nRun `%s -confirm` within 5 minutes to proceed.", parsed.Cmd.Trigger.Names[0]))
+	return out.String(), nil
+}
+
+// confirmMassAction starts the previewed mass action in the background
+func confirmMassAction(config *Config, parsed *dcmd.Data, p Punishment) (interface{}, error) {
+	author := parsed.Msg.Author
+	key := RedisKeyMassAction(parsed.GS.ID, author.ID)
+
+	var serialized []byte
+	err := common.RedisPool.Do(radix.Cmd(&serialized, "GET", key))
+	if err != nil {
+		return nil, err
+	}
+
+	var pending pendingMassAction
+	if len(serialized) < 1 || json.Unmarshal(serialized, &pending) != nil || pending.Punishment != p {
+		return "Nothing to confirm, run the command without -confirm first to get a preview", nil
+	}
+
+	locked, err := common.TryLockRedisKey(RedisKeyMassActionLock(parsed.GS.ID), 60*30)
+	if err != nil {
+		return nil, err
+	}
+
+	if !locked {
+		return "There's already a mass action in progress on this server", nil
+	}
+
+	common.RedisPool.Do(radix.Cmd(nil, "DEL", key))
+
+	progressMsg, err := common.BotSession.ChannelMessageSend(parsed.Msg.ChannelID, fmt.Sprintf("Working... done with 0/%d users", len(pending.Users)))
+	if err != nil {
+		common.UnlockRedisKey(RedisKeyMassActionLock(parsed.GS.ID))
+		return nil, err
+	}
+
+	go runMassAction(config, parsed.GS.ID, author, p, &pending, progressMsg)
+	return nil, nil
+}
+
+// runMassAction executes the mass action, keeping the progress message updated, it's ran in the background as it can take a while
+func runMassAction(config *Config, guildID int64, author *discordgo.User, p Punishment, pending *pendingMassAction, progressMsg *discordgo.Message) {
+	defer common.UnlockRedisKey(RedisKeyMassActionLock(guildID))
+
+	// don't post every single one to the modlog, a single summary is posted instead
+	individualConfig := *config
+	individualConfig.ActionChannel = ""
+
+	action := MABanned
+	if p == PunishmentKick {
+		action = MAKick
+	}
+
+	affected := make([]*discordgo.User, 0, len(pending.Users))
+	failed := 0
+	lastProgress := time.Now()
+	for i, id := range pending.Users {
+		target := &discordgo.User{ID: id, Username: "unknown", Discriminator: "????"}
+		if ms, _ := bot.GetMember(guildID, id); ms != nil {
+			target = ms.DGoUser()
+		}
+
+		// the discordgo ratelimiter takes care of pacing these according to the ratelimits,
+		// and we already have the ID's so there's no need to look up users that are not on the server in the audit log
+		var err error
+		if p == PunishmentKick {
+			err = kickUser(&individualConfig, guildID, 0, author, pending.Reason, target, false)
+		} else {
+			err = banUserWithDuration(&individualConfig, guildID, 0, author, pending.Reason, target, 0, false)
+		}
+
+		if err != nil {
+			logrus.WithError(err).WithField("guild", guildID).WithField("user", id).Warn("[moderation] failed executing mass action")
+			failed++
+		} else {
+			affected = append(affected, target)
+		}
+
+		if time.Since(lastProgress) > time.Second*5 {
+			lastProgress = time.Now()
+			common.BotSession.ChannelMessageEdit(progressMsg.ChannelID, progressMsg.ID, fmt.Sprintf("Working... done with %d/%d users", i+1, len(pending.Users)))
+		}
+	}
+
+	if len(affected) > 0 {
+		err := CreateMassModlogEmbed(config.IntActionChannel(), author, action, affected, pending.Reason)
+		if err != nil {
+			logrus.WithError(err).WithField("guild", guildID).Error("[moderation] failed creating mass action modlog entry")
+		}
+	}
+
+	resp := fmt.Sprintf("%s%s %d users", action.Emoji, action.Prefix, len(affected))
+	if failed > 0 {
+		resp += fmt.Sprintf(", failed on %d users (missing permissions or they're no longer on the server?)", failed)
+	}
+
+	common.BotSession.ChannelMessageEdit(progressMsg.ChannelID, progressMsg.ID, resp)
+}
+
+func massActionUserString(gs *dstate.GuildState, userID int64) string {
+	if ms := gs.Member(true, userID); ms != nil && ms.MemberSet {
+		return fmt.Sprintf("`%s#%04d` (%d)", ms.Username, ms.Discriminator, ms.ID)
+	}
+
+	return fmt.Sprintf("`%d` (not on the server)", userID)
+}
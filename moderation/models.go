@@ -44,6 +44,7 @@ type Config struct {
 	WarnIncludeChannelLogs bool
 	WarnSendToModlog       bool
 	WarnMessage            string `valid:"template,5000"`
+	WarnEscalationEnabled  bool
 
 	// Misc
 	CleanEnabled  bool
@@ -53,9 +54,22 @@ type Config struct {
 	LogUnbans     bool
 	LogBans       bool
 
+	ReportDMOnResolve bool
+
+	// Quarantine
+	QuarantineEnabled bool
+	QuarantineRole    string `valid:"role,true"`
+
+	// Ban appeals
+	AppealsEnabled bool
+	AppealsChannel string `valid:"channel,true"`
+
 	GiveRoleCmdEnabled bool
 	GiveRoleCmdModlog  bool
 	GiveRoleCmdRoles   pq.Int64Array `gorm:"type:bigint[]" valid:"role,true"`
+
+	// Not stored, set by automod on its copy of the config to link the cases it creates to the rule that triggered them
+	AutomodTrigger string `gorm:"-" json:"-" schema:"-"`
 }
 
 func (c *Config) IntMuteRole() (r int64) {
@@ -73,6 +87,22 @@ func (c *Config) IntReportChannel() (r int64) {
 	return
 }
 
+func (c *Config) IntQuarantineRole() (r int64) {
+	r, _ = strconv.ParseInt(c.QuarantineRole, 10, 64)
+	return
+}
+
+func (c *Config) IntAppealsChannel() (r int64) {
+	r, _ = strconv.ParseInt(c.AppealsChannel, 10, 64)
+	return
+}
+
+// TracksOutsideActions returns true if bans and kicks done outside the bot should be looked up in the audit log,
+// this is only done for guilds using the moderation plugin as every lookup waits and calls the audit log api
+func (c *Config) TracksOutsideActions() bool {
+	return c.IntActionChannel() != 0 || c.KickEnabled || c.BanEnabled || c.MuteEnabled || c.WarnCommandsEnabled
+}
+
 func (c *Config) GetName() string {
 	return "moderation"
 }
@@ -48,7 +48,7 @@ func RegisterPlugin() {
 	common.RegisterPlugin(plugin)
 
 	configstore.RegisterConfig(configstore.SQL, &Config{})
-	common.GORM.AutoMigrate(&Config{}, &WarningModel{}, &MuteModel{})
+	common.GORM.AutoMigrate(&Config{}, &WarningModel{}, &MuteModel{}, &ModerationCase{}, &WarningEscalationStep{}, &LockedChannel{}, &BanAppeal{}, &Report{}, &QuarantinedMember{}, &CleanLogEntry{}, &BanSharePartner{}, &SharedBanSuggestion{})
 }
 
 func getConfigIfNotSet(guildID int64, config *Config) (*Config, error) {
@@ -69,5 +69,8 @@ func GetConfig(guildID int64) (*Config, error) {
 	if err == configstore.ErrNotFound {
 		err = nil
 	}
-	return &config, err
+
+	// on a cache miss the cache keeps a pointer to config, return a copy so callers changing it don't change the cached one
+	c := config
+	return &c, err
 }
@@ -0,0 +1,39 @@
+package moderation
+
+import (
+	"github.com/jonas747/yagpdb/common/configstore"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+type testConfigStorage struct{}
+
+func (s *testConfigStorage) GetGuildConfig(ctx context.Context, guildID int64, dest configstore.GuildConfig) error {
+	config := dest.(*Config)
+	config.GuildID = guildID
+	config.ActionChannel = "1"
+	return nil
+}
+
+func (s *testConfigStorage) SetGuildConfig(ctx context.Context, conf configstore.GuildConfig) error {
+	return nil
+}
+
+func TestGetConfigNotShared(t *testing.T) {
+	configstore.RegisterConfig(&testConfigStorage{}, &Config{})
+
+	// the first call is a cache miss, the second a cache hit
+	for i := 0; i < 2; i++ {
+		config, err := GetConfig(1)
+		if err != nil {
+			t.Fatal("failed retrieving config: ", err)
+		}
+
+		if config.ActionChannel != "1" || config.AutomodTrigger != "" {
+			t.Fatalf("#%d: config was changed by a previous caller: ActionChannel: %q, AutomodTrigger: %q", i, config.ActionChannel, config.AutomodTrigger)
+		}
+
+		config.ActionChannel = ""
+		config.AutomodTrigger = "Automod"
+	}
+}
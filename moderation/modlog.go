@@ -41,6 +41,13 @@ func CreateModlogEmbed(channelID int64, author *discordgo.User, action ModlogAct
 		return nil
 	}
 
+	_, err := createModlogEmbed(channelID, author, action, target, reason, logLink, 0)
+	return err
+}
+
+// createModlogEmbed sends the modlog entry, caseNumber is shown in the footer if it's not 0
+func createModlogEmbed(channelID int64, author *discordgo.User, action ModlogAction, target *discordgo.User, reason, logLink string, caseNumber int64) (*discordgo.Message, error) {
+
 	emptyAuthor := false
 	if author == nil {
 		emptyAuthor = true
@@ -72,22 +79,69 @@ func CreateModlogEmbed(channelID int64, author *discordgo.User, action ModlogAct
 		embed.Description += " ([Logs](" + logLink + "))"
 	}
 
-	if action.Footer != "" {
+	footer := action.Footer
+	if caseNumber != 0 {
+		footer = fmt.Sprintf("Case #%d", caseNumber)
+		if action.Footer != "" {
+			footer += " | " + action.Footer
+		}
+	}
+
+	if footer != "" {
 		embed.Footer = &discordgo.MessageEmbedFooter{
-			Text: action.Footer,
+			Text: footer,
 		}
 	}
 
 	m, err := common.BotSession.ChannelMessageSendEmbed(channelID, embed)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if emptyAuthor {
-		placeholder := fmt.Sprintf("Asssign an author and reason to this using **'reason %d your-reason-here`**", m.ID)
+		id := m.ID
+		if caseNumber != 0 {
+			id = caseNumber
+		}
+
+		placeholder := fmt.Sprintf("Asssign an author and reason to this using **'reason %d your-reason-here`**", id)
 		updateEmbedReason(nil, placeholder, embed)
 		_, err = common.BotSession.ChannelMessageEditEmbed(channelID, m.ID, embed)
 	}
+	return m, err
+}
+
+// CreateMassModlogEmbed creates a single modlog entry summarizing a action performed on many users at once
+func CreateMassModlogEmbed(channelID int64, author *discordgo.User, action ModlogAction, targets []*discordgo.User, reason string) error {
+	if channelID == 0 {
+		return nil
+	}
+
+	if reason == "" {
+		reason = "(no reason specified)"
+	}
+
+	var users strings.Builder
+	for i, v := range targets {
+		line := fmt.Sprintf("%s#%s *(ID %d)*\n", v.Username, v.Discriminator, v.ID)
+		if users.Len()+len(line) > 1700 {
+			users.WriteString(fmt.Sprintf("...and %d more", len(targets)-i))
+			break
+		}
+
+		users.WriteString(line)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    fmt.Sprintf("%s#%s (ID %d)", author.Username, author.Discriminator, author.ID),
+			IconURL: discordgo.EndpointUserAvatar(author.ID, author.Avatar),
+		},
+		Color:       action.Color,
+		Description: fmt.Sprintf("**%s%s %d users**\n📄**Reason:** %s\n\n%s", action.Emoji, action.Prefix, len(targets), reason, users.String()),
+	}
+
+	_, err := common.BotSession.ChannelMessageSendEmbed(channelID, embed)
 	return err
 }
 
@@ -45,18 +45,26 @@ func (p *Plugin) BotInit() {
 	// scheduledevents.RegisterEventHandler("mod_unban", handleUnbanLegacy)
 	scheduledevents2.RegisterHandler("moderation_unmute", ScheduledUnmuteData{}, handleScheduledUnmute)
 	scheduledevents2.RegisterHandler("moderation_unban", ScheduledUnbanData{}, handleScheduledUnban)
+	scheduledevents2.RegisterHandler("moderation_unlock_channels", ScheduledUnlockData{}, handleScheduledUnlock)
+	scheduledevents2.RegisterHandler("moderation_revert_slowmode", ScheduledSlowmodeRevertData{}, handleScheduledSlowmodeRevert)
+	scheduledevents2.RegisterHandler("moderation_unquarantine", ScheduledUnquarantineData{}, handleScheduledUnquarantine)
 	scheduledevents2.RegisterLegacyMigrater("unmute", handleMigrateScheduledUnmute)
 	scheduledevents2.RegisterLegacyMigrater("mod_unban", handleMigrateScheduledUnban)
 
 	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleGuildBanAddRemove), eventsystem.EventGuildBanAdd, eventsystem.EventGuildBanRemove)
 	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleGuildMemberRemove), eventsystem.EventGuildMemberRemove)
+	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleAppealReaction), eventsystem.EventMessageReactionAdd)
+	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleReportReaction), eventsystem.EventMessageReactionAdd)
+	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleSharedBanReaction), eventsystem.EventMessageReactionAdd)
 	eventsystem.AddHandler(LockMemberMuteMW(HandleMemberJoin), eventsystem.EventGuildMemberAdd)
+	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleQuarantineMemberJoin), eventsystem.EventGuildMemberAdd)
 	eventsystem.AddHandler(LockMemberMuteMW(HandleGuildMemberUpdate), eventsystem.EventGuildMemberUpdate)
 
 	eventsystem.AddHandler(bot.ConcurrentEventHandler(HandleGuildCreate), eventsystem.EventGuildCreate)
 	eventsystem.AddHandler(HandleChannelCreateUpdate, eventsystem.EventChannelUpdate, eventsystem.EventChannelUpdate)
 
 	pubsub.AddHandler("mod_refresh_mute_override", HandleRefreshMuteOverrides, nil)
+	pubsub.AddHandler("mod_shared_ban", HandleSharedBanEvent, SharedBanData{})
 }
 
 type ScheduledUnmuteData struct {
@@ -150,18 +158,24 @@ func RefreshMuteOverrideForChannel(config *Config, channel *discordgo.Channel) {
 		return
 	}
 
+	denyRolePermsInChannel(channel, config.IntMuteRole(), MuteDeniedChannelPerms)
+}
+
+// denyRolePermsInChannel makes sure the role's overwrite in the channel denies perms, and doesn't allow any of them,
+// while keeping the rest of the overwrite as it is. It's used for both the mute role and lockdowns
+func denyRolePermsInChannel(channel *discordgo.Channel, roleID int64, perms int) error {
 	var override *discordgo.PermissionOverwrite
 
 	// Check for existing override
 	for _, v := range channel.PermissionOverwrites {
-		if v.Type == "role" && v.ID == config.IntMuteRole() {
+		if v.Type == "role" && v.ID == roleID {
 			override = v
 			break
 		}
 	}
 
 	allows := 0
-	denies := MuteDeniedChannelPerms
+	denies := perms
 	changed := true
 
 	if override != nil {
@@ -169,22 +183,24 @@ func RefreshMuteOverrideForChannel(config *Config, channel *discordgo.Channel) {
 		denies = override.Deny
 		changed = false
 
-		if (allows & MuteDeniedChannelPerms) != 0 {
-			// One of the mute permissions was in the allows, remove it
-			allows &= ^MuteDeniedChannelPerms
+		if (allows & perms) != 0 {
+			// One of the permissions was in the allows, remove it
+			allows &= ^perms
 			changed = true
 		}
 
-		if (denies & MuteDeniedChannelPerms) != MuteDeniedChannelPerms {
-			// Missing one of the mute permissions
-			denies |= MuteDeniedChannelPerms
+		if (denies & perms) != perms {
+			// Missing one of the permissions
+			denies |= perms
 			changed = true
 		}
 	}
 
-	if changed {
-		common.BotSession.ChannelPermissionSet(channel.ID, config.IntMuteRole(), "role", allows, denies)
+	if !changed {
+		return nil
 	}
+
+	return common.BotSession.ChannelPermissionSet(channel.ID, roleID, "role", allows, denies)
 }
 
 func HandleGuildBanAddRemove(evt *eventsystem.EventData) {
@@ -216,6 +232,12 @@ func HandleGuildBanAddRemove(evt *eventsystem.EventData) {
 
 		var i int
 		common.RedisPool.Do(radix.Cmd(&i, "GET", RedisKeyUnbannedUser(guildID, user.ID)))
+		if i > 1 {
+			// Unbanned through UnbanUser, which creates the case itself
+			common.RedisPool.Do(radix.Cmd(nil, "DEL", RedisKeyUnbannedUser(guildID, user.ID)))
+			return
+		}
+
 		if i > 0 {
 			// The bot was the one that performed the unban
 			common.RedisPool.Do(radix.Cmd(nil, "DEL", RedisKeyUnbannedUser(guildID, user.ID)))
@@ -232,7 +254,8 @@ func HandleGuildBanAddRemove(evt *eventsystem.EventData) {
 		return
 	}
 
-	if config.IntActionChannel() == 0 {
+	// same rule as for kicks, the case is created even without a modlog channel for guilds using moderation
+	if !config.TracksOutsideActions() {
 		return
 	}
 
@@ -255,21 +278,17 @@ func HandleGuildBanAddRemove(evt *eventsystem.EventData) {
 		}
 	}
 
-	if (action == MAUnbanned && !config.LogUnbans && !botPerformed) ||
-		(action == MABanned && !config.LogBans) {
-		return
-	}
-
 	// The bot only unbans people in the case of timed bans
 	if botPerformed {
 		author = common.BotUser
 		reason = "Timed ban expired"
 	}
 
-	err = CreateModlogEmbed(config.IntActionChannel(), author, action, user, reason, "")
-	if err != nil {
-		logrus.WithError(err).WithField("guild", guildID).Error("Failed sending " + action.Prefix + " log message")
-	}
+	// a case is always created, but it's only posted to the modlog if enabled
+	postModlog := !((action == MAUnbanned && !config.LogUnbans && !botPerformed) ||
+		(action == MABanned && !config.LogBans))
+
+	createCaseLogErr(config, guildID, author, action, user, reason, postModlog)
 }
 
 func HandleGuildMemberRemove(evt *eventsystem.EventData) {
@@ -281,7 +300,8 @@ func HandleGuildMemberRemove(evt *eventsystem.EventData) {
 		return
 	}
 
-	if config.IntActionChannel() == 0 {
+	// The case is created even without a modlog channel so that the kick is attributed to the moderator in the stats.
+	if !config.TracksOutsideActions() {
 		return
 	}
 
@@ -298,10 +318,7 @@ func HandleGuildMemberRemove(evt *eventsystem.EventData) {
 		return
 	}
 
-	err = CreateModlogEmbed(config.IntActionChannel(), author, MAKick, data.User, entry.Reason, "")
-	if err != nil {
-		logrus.WithError(err).WithField("guild", data.GuildID).Error("Failed sending kick log message")
-	}
+	createCaseLogErr(config, data.GuildID, author, MAKick, data.User, entry.Reason, true)
 }
 
 // Since updating mutes are now a complex operation with removing roles and whatnot,
@@ -2,6 +2,7 @@ package moderation
 
 import (
 	"fmt"
+	"github.com/jinzhu/gorm"
 	"github.com/jonas747/discordgo"
 	"github.com/jonas747/yagpdb/common"
 	"github.com/jonas747/yagpdb/web"
@@ -9,6 +10,8 @@ import (
 	"goji.io/pat"
 	"html/template"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func (p *Plugin) InitWeb() {
@@ -36,6 +39,48 @@ func (p *Plugin) InitWeb() {
 	subMux.Handle(pat.Post(""), postHandler)
 	subMux.Handle(pat.Post("/"), postHandler)
 	subMux.Handle(pat.Post("/clear_server_warnings"), clearServerWarnings)
+
+	addEscalationStep := web.ControllerPostHandler(HandleAddEscalationStep, getHandler, EscalationStepForm{}, "Added escalation step")
+	deleteEscalationStep := web.ControllerPostHandler(HandleDeleteEscalationStep, getHandler, nil, "Deleted escalation step")
+	subMux.Handle(pat.Post("/escalation_steps"), addEscalationStep)
+	subMux.Handle(pat.Post("/escalation_steps/:stepID/delete"), deleteEscalationStep)
+
+	casesHandler := web.ControllerHandler(HandleCases, "cp_moderation_cases")
+	subMux.Handle(pat.Get("/cases"), casesHandler)
+	subMux.Handle(pat.Get("/cases/"), casesHandler)
+
+	statsHandler := web.ControllerHandler(HandleModStats, "cp_moderation_stats")
+	subMux.Handle(pat.Get("/stats"), statsHandler)
+	subMux.Handle(pat.Get("/stats/"), statsHandler)
+
+	reportsHandler := web.ControllerHandler(HandleReports, "cp_moderation_reports")
+	subMux.Handle(pat.Get("/reports"), reportsHandler)
+	subMux.Handle(pat.Get("/reports/"), reportsHandler)
+
+	banSharingHandler := web.ControllerHandler(HandleBanSharing, "cp_moderation_ban_sharing")
+	addBanSharePartner := web.ControllerPostHandler(HandleAddBanSharePartner, banSharingHandler, BanSharePartnerForm{}, "Added ban sharing partner")
+	removeBanSharePartner := web.ControllerPostHandler(HandleRemoveBanSharePartner, banSharingHandler, nil, "Removed ban sharing partner")
+	subMux.Handle(pat.Get("/ban_sharing"), banSharingHandler)
+	subMux.Handle(pat.Get("/ban_sharing/"), banSharingHandler)
+	subMux.Handle(pat.Post("/ban_sharing"), addBanSharePartner)
+	subMux.Handle(pat.Post("/ban_sharing/:partnerID/remove"), removeBanSharePartner)
+
+	// the appeal form is for banned users, so it's not under the control panel
+	appealMux := goji.SubMux()
+	web.RootMux.Handle(pat.New("/appeal/:server"), appealMux)
+	web.RootMux.Handle(pat.New("/appeal/:server/*"), appealMux)
+
+	appealMux.Use(web.ActiveServerMW)
+	appealMux.Use(web.RequireActiveServer)
+	appealMux.Use(web.LoadCoreConfigMiddleware)
+
+	appealGetHandler := web.ControllerHandler(HandleAppeal, "moderation_appeal")
+	appealPostHandler := web.RequireSessionMiddleware(web.ControllerPostHandler(HandlePostAppeal, appealGetHandler, AppealForm{}, ""))
+
+	appealMux.Handle(pat.Get(""), appealGetHandler)
+	appealMux.Handle(pat.Get("/"), appealGetHandler)
+	appealMux.Handle(pat.Post(""), appealPostHandler)
+	appealMux.Handle(pat.Post("/"), appealPostHandler)
 }
 
 // The moderation page itself
@@ -52,6 +97,12 @@ func HandleModeration(w http.ResponseWriter, r *http.Request) (web.TemplateData,
 		templateData["ModConfig"] = config
 	}
 
+	steps, err := GetEscalationSteps(activeGuild.ID)
+	if err != nil {
+		return templateData, err
+	}
+	templateData["EscalationSteps"] = steps
+
 	return templateData, nil
 }
 
@@ -84,8 +135,362 @@ func HandleClearServerWarnings(w http.ResponseWriter, r *http.Request) (web.Temp
 	return templateData, nil
 }
 
+type EscalationStepForm struct {
+	Warnings   int `valid:",1,1000"`
+	WithinDays int `valid:",0,3650"`
+	Action     int `valid:",0,2"`
+	Duration   int `valid:",0,525600"`
+}
+
+func HandleAddEscalationStep(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+	templateData["VisibleURL"] = "/manage/" + discordgo.StrID(activeGuild.ID) + "/moderation/"
+
+	form := ctx.Value(common.ContextKeyParsedForm).(*EscalationStepForm)
+
+	var count int
+	err := common.GORM.Model(&WarningEscalationStep{}).Where("guild_id = ?", activeGuild.ID).Count(&count).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	if count >= MaxEscalationSteps {
+		return templateData, web.NewPublicError(fmt.Sprintf("Max %d escalation steps", MaxEscalationSteps))
+	}
+
+	if EscalationAction(form.Action) == EscalationMute && form.Duration < 1 {
+		return templateData, web.NewPublicError("Mutes needs a duration")
+	}
+
+	step := &WarningEscalationStep{
+		GuildID:    activeGuild.ID,
+		Warnings:   form.Warnings,
+		WithinDays: form.WithinDays,
+		Action:     EscalationAction(form.Action),
+		Duration:   form.Duration,
+	}
+
+	err = common.GORM.Create(step).Error
+	return templateData, err
+}
+
+func HandleDeleteEscalationStep(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+	templateData["VisibleURL"] = "/manage/" + discordgo.StrID(activeGuild.ID) + "/moderation/"
+
+	stepID, _ := strconv.ParseInt(pat.Param(r, "stepID"), 10, 64)
+	err := common.GORM.Where("guild_id = ? AND id = ?", activeGuild.ID, stepID).Delete(WarningEscalationStep{}).Error
+	return templateData, err
+}
+
+const casesPerWebPage = 50
+
+// Lists the cases, optionally filtered by user, action and reason
+func HandleCases(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	activeGuild, templateData := web.GetBaseCPContextData(r.Context())
+
+	query := common.GORM.Model(&ModerationCase{}).Where("guild_id = ?", activeGuild.ID)
+
+	userID, _ := strconv.ParseInt(r.FormValue("user"), 10, 64)
+	if userID != 0 {
+		query = query.Where("user_id = ? OR author_id = ?", userID, userID)
+	}
+
+	action := r.FormValue("action")
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	search := strings.TrimSpace(r.FormValue("search"))
+	if search != "" {
+		escaped := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(search)
+		query = query.Where("reason ILIKE ? OR username_discrim ILIKE ? OR automod_trigger ILIKE ?", "%"+escaped+"%", "%"+escaped+"%", "%"+escaped+"%")
+	}
+
+	var total int
+	err := query.Count(&total).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	page, _ := strconv.Atoi(r.FormValue("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	var cases []*ModerationCase
+	err = query.Order("case_number desc").Offset((page - 1) * casesPerWebPage).Limit(casesPerWebPage).Find(&cases).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	templateData["ModCases"] = cases
+	templateData["ModCasesTotal"] = total
+	templateData["ModCasesPage"] = page
+	templateData["ModCasesHasNext"] = page*casesPerWebPage < total
+	templateData["ModCasesActions"] = []ModlogAction{MABanned, MAUnbanned, MAKick, MAMute, MAUnmute, MAWarned, MAQuarantine, MAUnquarantine}
+	templateData["SearchUser"] = r.FormValue("user")
+	templateData["SearchAction"] = action
+	templateData["SearchQuery"] = search
+
+	return templateData, nil
+}
+
 var _ web.PluginWithServerHomeWidget = (*Plugin)(nil)
 
+// Shows the number of actions per moderator in the selected period
+func HandleModStats(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	activeGuild, templateData := web.GetBaseCPContextData(r.Context())
+
+	days := 30
+	if v, err := strconv.Atoi(r.FormValue("days")); err == nil && v >= 0 {
+		days = v
+	}
+
+	stats, err := GetModeratorStats(activeGuild.ID, modStatsSince(days), 0)
+	if err != nil {
+		return templateData, err
+	}
+
+	templateData["ModStats"] = stats
+	templateData["ModStatsDays"] = days
+	templateData["ModStatsPeriods"] = ModStatsPeriods
+	templateData["ModStatsPeriodName"] = modStatsPeriodName(days)
+	return templateData, nil
+}
+
+// Lists the open and claimed reports by default, or all reports with the specified status
+func HandleReports(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	activeGuild, templateData := web.GetBaseCPContextData(r.Context())
+
+	query := common.GORM.Where("guild_id = ?", activeGuild.ID)
+
+	status, err := strconv.Atoi(r.FormValue("status"))
+	if err == nil && status >= int(ReportOpen) && status <= int(ReportDismissed) {
+		query = query.Where("status = ?", status)
+		templateData["ReportsStatus"] = strconv.Itoa(status)
+	} else {
+		query = query.Where("status IN (?)", []ReportStatus{ReportOpen, ReportClaimed})
+		templateData["ReportsStatus"] = ""
+	}
+
+	var reports []*Report
+	err = query.Order("report_number desc").Limit(100).Find(&reports).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	templateData["Reports"] = reports
+	templateData["ReportStatuses"] = []ReportStatus{ReportOpen, ReportClaimed, ReportResolved, ReportDismissed}
+	return templateData, nil
+}
+
+// BanSharePartnerView is a partner as shown on the ban sharing page
+type BanSharePartnerView struct {
+	GuildID   int64
+	Name      string
+	AutoApply bool
+	Mutual    bool
+}
+
+func banSharePartnerName(guildID int64) string {
+	guild, err := common.GetGuild(guildID)
+	if err != nil || guild == nil {
+		return "Unknown server"
+	}
+
+	return guild.Name
+}
+
+// Lists the servers this server shares bans with, and the servers that wants to share bans with this server
+func HandleBanSharing(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	activeGuild, templateData := web.GetBaseCPContextData(r.Context())
+
+	partners, err := GetBanSharePartners(activeGuild.ID, false)
+	if err != nil {
+		return templateData, err
+	}
+
+	mutual, err := GetBanSharePartners(activeGuild.ID, true)
+	if err != nil {
+		return templateData, err
+	}
+
+	views := make([]*BanSharePartnerView, 0, len(partners))
+	for _, v := range partners {
+		view := &BanSharePartnerView{
+			GuildID:   v.PartnerGuildID,
+			Name:      banSharePartnerName(v.PartnerGuildID),
+			AutoApply: v.AutoApply,
+		}
+
+		for _, m := range mutual {
+			if m.PartnerGuildID == v.PartnerGuildID {
+				view.Mutual = true
+				break
+			}
+		}
+
+		views = append(views, view)
+	}
+
+	// servers that has added this server but haven't been added back
+	var incoming []*BanSharePartner
+	err = common.GORM.Where("partner_guild_id = ?", activeGuild.ID).
+		Where("guild_id NOT IN (SELECT partner_guild_id FROM moderation_ban_share_partners WHERE guild_id = ?)", activeGuild.ID).
+		Order("id asc").Limit(MaxBanSharePartners).Find(&incoming).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	incomingViews := make([]*BanSharePartnerView, 0, len(incoming))
+	for _, v := range incoming {
+		incomingViews = append(incomingViews, &BanSharePartnerView{GuildID: v.GuildID, Name: banSharePartnerName(v.GuildID)})
+	}
+
+	templateData["BanSharePartners"] = views
+	templateData["BanShareIncoming"] = incomingViews
+	templateData["MaxBanSharePartners"] = MaxBanSharePartners
+	return templateData, nil
+}
+
+type BanSharePartnerForm struct {
+	PartnerGuildID int64
+	AutoApply      bool
+}
+
+// Adds a partner, or updates the settings if it's already added
+func HandleAddBanSharePartner(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	form := ctx.Value(common.ContextKeyParsedForm).(*BanSharePartnerForm)
+	if form.PartnerGuildID == activeGuild.ID {
+		return templateData, web.NewPublicError("Can't share bans with the same server")
+	}
+
+	onGuild, err := common.BotIsOnGuild(form.PartnerGuildID)
+	if err != nil {
+		return templateData, err
+	}
+
+	if !onGuild {
+		return templateData, web.NewPublicError("The bot is not on that server")
+	}
+
+	var existing BanSharePartner
+	err = common.GORM.Where("guild_id = ? AND partner_guild_id = ?", activeGuild.ID, form.PartnerGuildID).First(&existing).Error
+	if err == nil {
+		err = common.GORM.Model(&existing).Update("auto_apply", form.AutoApply).Error
+		return templateData, err
+	} else if err != gorm.ErrRecordNotFound {
+		return templateData, err
+	}
+
+	var count int
+	err = common.GORM.Model(&BanSharePartner{}).Where("guild_id = ?", activeGuild.ID).Count(&count).Error
+	if err != nil {
+		return templateData, err
+	}
+
+	if count >= MaxBanSharePartners {
+		return templateData, web.NewPublicError(fmt.Sprintf("Max %d ban sharing partners", MaxBanSharePartners))
+	}
+
+	err = common.GORM.Create(&BanSharePartner{
+		GuildID:        activeGuild.ID,
+		PartnerGuildID: form.PartnerGuildID,
+		AutoApply:      form.AutoApply,
+	}).Error
+	return templateData, err
+}
+
+func HandleRemoveBanSharePartner(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+
+	partnerID, _ := strconv.ParseInt(pat.Param(r, "partnerID"), 10, 64)
+	err := common.GORM.Where("guild_id = ? AND partner_guild_id = ?", activeGuild.ID, partnerID).Delete(BanSharePartner{}).Error
+	return templateData, err
+}
+
+type AppealForm struct {
+	Text string `valid:",10,1500,trimspace"`
+}
+
+// The ban appeal form
+func HandleAppeal(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+	templateData["AppealURL"] = AppealURL(activeGuild.ID)
+
+	config, err := GetConfig(activeGuild.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	templateData["AppealsEnabled"] = config.AppealsEnabled && config.IntAppealsChannel() != 0
+	if !config.AppealsEnabled {
+		return templateData, nil
+	}
+
+	user := web.ContextUser(ctx)
+	if user == nil {
+		return templateData, nil
+	}
+
+	banCase, err := LatestBanCase(activeGuild.ID, user.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	canAppeal, reason, err := CanAppeal(activeGuild.ID, user.ID, banCase)
+	if err != nil {
+		return templateData, err
+	}
+
+	templateData["BanCase"] = banCase
+	templateData["CanAppeal"] = canAppeal
+	templateData["CantAppealReason"] = reason
+
+	return templateData, nil
+}
+
+func HandlePostAppeal(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
+	ctx := r.Context()
+	activeGuild, templateData := web.GetBaseCPContextData(ctx)
+	user := web.ContextUser(ctx)
+
+	config, err := GetConfig(activeGuild.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	if !config.AppealsEnabled {
+		return templateData, web.NewPublicError("Appeals are not enabled on this server")
+	}
+
+	banCase, err := LatestBanCase(activeGuild.ID, user.ID)
+	if err != nil {
+		return templateData, err
+	}
+
+	canAppeal, reason, err := CanAppeal(activeGuild.ID, user.ID, banCase)
+	if err != nil {
+		return templateData, err
+	}
+
+	if !canAppeal {
+		return templateData, web.NewPublicError(reason)
+	}
+
+	form := ctx.Value(common.ContextKeyParsedForm).(*AppealForm)
+	_, err = CreateAppeal(config, activeGuild.ID, user, banCase, form.Text)
+	return templateData, err
+}
+
 func (p *Plugin) LoadServerHomeWidget(w http.ResponseWriter, r *http.Request) (web.TemplateData, error) {
 	activeGuild, templateData := web.GetBaseCPContextData(r.Context())
 
@@ -53,8 +53,9 @@ func getMemberWithFallback(gs *dstate.GuildState, user *discordgo.User) (ms *dst
 	return ms, false
 }
 
-// Kick or bans someone, uploading a hasebin log, and sending the report message in the action channel
-func punish(config *Config, p Punishment, guildID, channelID int64, author *discordgo.User, reason string, user *discordgo.User, duration time.Duration) error {
+// Kick or bans someone, uploading a hasebin log, and sending the report message in the action channel.
+// If lookupAuditLog is set and the user is not on the server, the audit log is checked for their user details
+func punish(config *Config, p Punishment, guildID, channelID int64, author *discordgo.User, reason string, user *discordgo.User, duration time.Duration, lookupAuditLog bool) error {
 
 	config, err := getConfigIfNotSet(guildID, config)
 	if err != nil {
@@ -100,7 +101,7 @@ func punish(config *Config, p Punishment, guildID, channelID int64, author *disc
 
 	logrus.Println("MODERATION:", author.Username, action.Prefix, user.Username, "cause", reason)
 
-	if memberNotFound {
+	if memberNotFound && lookupAuditLog {
 		// Wait a tiny bit to make sure the audit log is updated
 		time.Sleep(time.Second * 3)
 
@@ -127,8 +128,7 @@ func punish(config *Config, p Punishment, guildID, channelID int64, author *disc
 		}
 	}
 
-	actionChannel := config.IntActionChannel()
-	err = CreateModlogEmbed(actionChannel, author, action, user, reason, logLink)
+	_, err = CreateCase(config, guildID, author, action, user, reason, logLink, duration, true)
 	return err
 }
 
@@ -155,16 +155,24 @@ func sendPunishDM(config *Config, dmMsg string, action ModlogAction, gs *dstate.
 		executed = "Failed executing template."
 	}
 
+	if action.Prefix == MABanned.Prefix && config.AppealsEnabled && config.IntAppealsChannel() != 0 {
+		executed += "\n\nYou can appeal this ban at <" + AppealURL(gs.ID) + ">"
+	}
+
 	go bot.SendDM(member.ID, "**"+bot.GuildName(gs.ID)+":** "+executed)
 }
 
 func KickUser(config *Config, guildID, channelID int64, author *discordgo.User, reason string, user *discordgo.User) error {
+	return kickUser(config, guildID, channelID, author, reason, user, true)
+}
+
+func kickUser(config *Config, guildID, channelID int64, author *discordgo.User, reason string, user *discordgo.User, lookupAuditLog bool) error {
 	config, err := getConfigIfNotSet(guildID, config)
 	if err != nil {
 		return common.ErrWithCaller(err)
 	}
 
-	err = punish(config, PunishmentKick, guildID, channelID, author, reason, user, 0)
+	err = punish(config, PunishmentKick, guildID, channelID, author, reason, user, 0, lookupAuditLog)
 	if err != nil {
 		return err
 	}
@@ -219,9 +227,13 @@ func DeleteMessages(channelID int64, filterUser int64, deleteNum, fetchNum int)
 }
 
 func BanUserWithDuration(config *Config, guildID, channelID int64, author *discordgo.User, reason string, user *discordgo.User, duration time.Duration) error {
+	return banUserWithDuration(config, guildID, channelID, author, reason, user, duration, true)
+}
+
+func banUserWithDuration(config *Config, guildID, channelID int64, author *discordgo.User, reason string, user *discordgo.User, duration time.Duration, lookupAuditLog bool) error {
 	// Set a key in redis that marks that this user has appeared in the modlog already
 	common.RedisPool.Do(radix.Cmd(nil, "SETEX", RedisKeyBannedUser(guildID, user.ID), "60", "1"))
-	err := punish(config, PunishmentBan, guildID, channelID, author, reason, user, duration)
+	err := punish(config, PunishmentBan, guildID, channelID, author, reason, user, duration, lookupAuditLog)
 	if err != nil {
 		return err
 	}
@@ -245,6 +257,30 @@ func BanUser(config *Config, guildID, channelID int64, author *discordgo.User, r
 	return BanUserWithDuration(config, guildID, channelID, author, reason, user, 0)
 }
 
+// UnbanUser unbans the user and creates a case for it
+func UnbanUser(config *Config, guildID int64, author *discordgo.User, reason string, user *discordgo.User) error {
+	config, err := getConfigIfNotSet(guildID, config)
+	if err != nil {
+		return common.ErrWithCaller(err)
+	}
+
+	// 2 marks that the case is created here, so that the unban event handler does not create another one
+	common.RedisPool.Do(radix.Cmd(nil, "SETEX", RedisKeyUnbannedUser(guildID, user.ID), "60", "2"))
+	err = common.BotSession.GuildBanDelete(guildID, user.ID)
+	if err != nil {
+		common.RedisPool.Do(radix.Cmd(nil, "DEL", RedisKeyUnbannedUser(guildID, user.ID)))
+		return err
+	}
+
+	_, err = seventsmodels.ScheduledEvents(qm.Where("event_name='moderation_unban' AND  guild_id = ? AND (data->>'user_id')::bigint = ?", guildID, user.ID)).DeleteAll(context.Background(), common.PQ)
+	common.LogIgnoreError(err, "[moderation] failed clearing unban events", nil)
+
+	logrus.Println("MODERATION:", author.Username, MAUnbanned.Prefix, user.Username, "cause", reason)
+
+	_, err = CreateCase(config, guildID, author, MAUnbanned, user, reason, "", 0, true)
+	return err
+}
+
 var (
 	ErrNoMuteRole = errors.New("No mute role")
 )
@@ -362,13 +398,14 @@ func MuteUnmuteUser(config *Config, mute bool, guildID, channelID int64, author
 		sendPunishDM(config, dmMsg, action, gs, author, member, time.Duration(duration)*time.Minute, reason)
 	}
 
-	// Create the modlog entry
-	logChannel, _ := strconv.ParseInt(config.ActionChannel, 10, 64)
-	if logChannel != 0 {
-		return CreateModlogEmbed(logChannel, author, action, member.DGoUser(), reason, logLink)
+	// Create the case and modlog entry
+	caseDuration := time.Duration(0)
+	if mute {
+		caseDuration = time.Duration(duration) * time.Minute
 	}
 
-	return nil
+	_, err = CreateCase(config, guildID, author, action, member.DGoUser(), reason, logLink, caseDuration, true)
+	return err
 }
 
 func AddMemberMuteRole(config *Config, id int64, currentRoles []int64) (removedRoles []int64, err error) {
@@ -453,12 +490,14 @@ func WarnUser(config *Config, guildID, channelID int64, author *discordgo.User,
 
 	// go bot.SendDM(target.ID, fmt.Sprintf("**%s**: You have been warned for: %s", bot.GuildName(guildID), message))
 
-	if config.WarnSendToModlog && config.ActionChannel != "" {
-		parsedActionChannel, _ := strconv.ParseInt(config.ActionChannel, 10, 64)
-		err = CreateModlogEmbed(parsedActionChannel, author, MAWarned, target, message, warning.LogsLink)
-		if err != nil {
-			return common.ErrWithCaller(err)
-		}
+	_, err = CreateCase(config, guildID, author, MAWarned, target, message, warning.LogsLink, 0, config.WarnSendToModlog)
+	if err != nil {
+		return common.ErrWithCaller(err)
+	}
+
+	err = escalateWarnings(config, guildID, channelID, target)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", guildID).Error("[moderation] failed escalating warnings")
 	}
 
 	return nil
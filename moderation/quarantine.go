@@ -0,0 +1,351 @@
+package moderation
+
+import (
+	"context"
+	"github.com/jinzhu/gorm"
+	"github.com/jonas747/dcmd"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/bot"
+	"github.com/jonas747/yagpdb/bot/eventsystem"
+	"github.com/jonas747/yagpdb/commands"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/jonas747/yagpdb/common/scheduledevents2"
+	seventsmodels "github.com/jonas747/yagpdb/common/scheduledevents2/models"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+	"strconv"
+	"time"
+)
+
+var (
+	MAQuarantine   = ModlogAction{Prefix: "Quarantined", Emoji: "☣", Color: 0x8c4bd6}
+	MAUnquarantine = ModlogAction{Prefix: "Unquarantined", Emoji: "🆓", Color: 0x62c65f}
+)
+
+var (
+	ErrNoQuarantineRole = errors.New("No quarantine role")
+)
+
+// QuarantinedMember holds the roles that were removed from a quarantined member, so that they can be restored exactly
+type QuarantinedMember struct {
+	common.SmallModel
+
+	GuildID int64 `gorm:"unique_index:idx_moderation_quarantined_guild_user"`
+	UserID  int64 `gorm:"unique_index:idx_moderation_quarantined_guild_user"`
+
+	AuthorID int64
+	Reason   string
+
+	RemovedRoles pq.Int64Array `gorm:"type:bigint[]"`
+
+	// Set if the member was released while not on the server, the roles are restored when they rejoin
+	PendingRestore bool
+}
+
+func (q *QuarantinedMember) TableName() string {
+	return "moderation_quarantined_members"
+}
+
+type ScheduledUnquarantineData struct {
+	UserID int64 `json:"user_id"`
+}
+
+var cmdQuarantine = &commands.YAGCommand{
+	CustomEnabled: true,
+	CmdCategory:   commands.CategoryModeration,
+	Name:          "Quarantine",
+	Description:   "Removes all roles from a member and gives them the quarantine role, the roles are restored when unquarantined",
+	Arguments: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Name: "User", Type: dcmd.UserID},
+		&dcmd.ArgDef{Name: "Duration", Default: time.Duration(0), Type: &commands.DurationArg{}},
+		&dcmd.ArgDef{Name: "Reason", Type: dcmd.String},
+	},
+	ArgumentCombos: [][]int{[]int{0, 1, 2}, []int{0, 2, 1}, []int{0, 1}, []int{0, 2}, []int{0}},
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		config, target, err := MBaseCmd(parsed, parsed.Args[0].Int64())
+		if err != nil {
+			return nil, err
+		}
+
+		reason, err := MBaseCmdSecond(parsed, SafeArgString(parsed, 2), true, discordgo.PermissionManageRoles, nil, config.QuarantineEnabled)
+		if err != nil {
+			return nil, err
+		}
+
+		member, err := bot.GetMember(parsed.GS.ID, target.ID)
+		if err != nil || member == nil {
+			return "Member not found", err
+		}
+
+		duration := parsed.Args[1].Value.(time.Duration)
+		err = QuarantineMember(config, parsed.GS.ID, parsed.Msg.ChannelID, parsed.Msg.Author, reason, member.ID, member.Roles, duration)
+		if err != nil {
+			if err == ErrNoQuarantineRole {
+				return "No quarantine role set up", nil
+			}
+
+			return nil, err
+		}
+
+		return GenericCmdResp(MAQuarantine, member.DGoUser(), duration, true, false), nil
+	},
+}
+
+var cmdUnquarantine = &commands.YAGCommand{
+	CustomEnabled: true,
+	CmdCategory:   commands.CategoryModeration,
+	Name:          "Unquarantine",
+	Description:   "Restores the roles of a quarantined member",
+	RequiredArgs:  1,
+	Arguments: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Name: "User", Type: dcmd.UserID},
+		&dcmd.ArgDef{Name: "Reason", Type: dcmd.String},
+	},
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		config, target, err := MBaseCmd(parsed, parsed.Args[0].Int64())
+		if err != nil {
+			return nil, err
+		}
+
+		reason, err := MBaseCmdSecond(parsed, SafeArgString(parsed, 1), true, discordgo.PermissionManageRoles, nil, config.QuarantineEnabled)
+		if err != nil {
+			return nil, err
+		}
+
+		released, err := UnquarantineMember(config, parsed.GS.ID, parsed.Msg.Author, reason, target)
+		if err != nil {
+			return nil, err
+		}
+
+		if !released {
+			return "That user is not quarantined", nil
+		}
+
+		return GenericCmdResp(MAUnquarantine, target, 0, false, true), nil
+	},
+}
+
+// QuarantineMember removes all roles except managed ones from the member, storing them and giving them the quarantine role.
+// If duration is above 0 the member is released after it
+func QuarantineMember(config *Config, guildID, channelID int64, author *discordgo.User, reason string, userID int64, currentRoles []int64, duration time.Duration) error {
+	config, err := getConfigIfNotSet(guildID, config)
+	if err != nil {
+		return common.ErrWithCaller(err)
+	}
+
+	quarantineRole := config.IntQuarantineRole()
+	if quarantineRole == 0 {
+		return ErrNoQuarantineRole
+	}
+
+	gs := bot.State.Guild(true, guildID)
+	if gs == nil {
+		return errors.New("guild not found")
+	}
+
+	// managed roles (bot and integration roles) can't be removed
+	keptRoles := []string{strconv.FormatInt(quarantineRole, 10)}
+	removedRoles := make([]int64, 0, len(currentRoles))
+	for _, r := range currentRoles {
+		if r == quarantineRole {
+			continue
+		}
+
+		role := gs.RoleCopy(true, r)
+		if role != nil && role.Managed {
+			keptRoles = append(keptRoles, strconv.FormatInt(r, 10))
+			continue
+		}
+
+		removedRoles = append(removedRoles, r)
+	}
+
+	var quarantine QuarantinedMember
+	err = common.GORM.Where("guild_id = ? AND user_id = ?", guildID, userID).First(&quarantine).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	// already quarantined, keep the roles from before the first quarantine along with any new ones
+	for _, r := range removedRoles {
+		if !common.ContainsInt64Slice(quarantine.RemovedRoles, r) {
+			quarantine.RemovedRoles = append(quarantine.RemovedRoles, r)
+		}
+	}
+
+	quarantine.GuildID = guildID
+	quarantine.UserID = userID
+	quarantine.AuthorID = author.ID
+	quarantine.Reason = reason
+	quarantine.PendingRestore = false
+
+	// store the roles before removing them, so they can always be restored
+	err = common.GORM.Save(&quarantine).Error
+	if err != nil {
+		return err
+	}
+
+	err = common.BotSession.GuildMemberEdit(guildID, userID, keptRoles)
+	if err != nil {
+		return err
+	}
+
+	err = clearScheduledUnquarantine(guildID, userID)
+	if err != nil {
+		return err
+	}
+
+	if duration > 0 {
+		err = scheduledevents2.ScheduleEvent("moderation_unquarantine", guildID, time.Now().Add(duration), &ScheduledUnquarantineData{
+			UserID: userID,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "failed scheduling unquarantine")
+		}
+	}
+
+	logLink := ""
+	if channelID != 0 {
+		logLink = CreateLogs(guildID, channelID, author)
+	}
+
+	target := &discordgo.User{ID: userID, Username: "unknown", Discriminator: "????"}
+	if ms, _ := bot.GetMember(guildID, userID); ms != nil {
+		target = ms.DGoUser()
+	}
+
+	action := MAQuarantine
+	if duration > 0 {
+		action.Footer = "Expires after: " + common.HumanizeDuration(common.DurationPrecisionMinutes, duration)
+	}
+
+	_, err = CreateCase(config, guildID, author, action, target, reason, logLink, duration, true)
+	return err
+}
+
+// UnquarantineMember restores the roles of the quarantined member, if the member is not on the server
+// they're restored once they rejoin. Returns false if the user was not quarantined
+func UnquarantineMember(config *Config, guildID int64, author *discordgo.User, reason string, target *discordgo.User) (bool, error) {
+	config, err := getConfigIfNotSet(guildID, config)
+	if err != nil {
+		return false, common.ErrWithCaller(err)
+	}
+
+	var quarantine QuarantinedMember
+	err = common.GORM.Where("guild_id = ? AND user_id = ? AND pending_restore = false", guildID, target.ID).First(&quarantine).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	err = clearScheduledUnquarantine(guildID, target.ID)
+	if err != nil {
+		return false, err
+	}
+
+	member, err := bot.GetMember(guildID, target.ID)
+	if err != nil || member == nil {
+		// not on the server, restore the roles when they rejoin
+		err = common.GORM.Model(&quarantine).Update("pending_restore", true).Error
+		if err != nil {
+			return false, err
+		}
+	} else {
+		err = restoreQuarantinedRoles(config, &quarantine, member.Roles)
+		if err != nil {
+			return false, err
+		}
+
+		target = member.DGoUser()
+	}
+
+	_, err = CreateCase(config, guildID, author, MAUnquarantine, target, reason, "", 0, true)
+	return true, err
+}
+
+// restoreQuarantinedRoles gives back the removed roles, removes the quarantine role and deletes the quarantine entry
+func restoreQuarantinedRoles(config *Config, quarantine *QuarantinedMember, currentRoles []int64) error {
+	gs := bot.State.Guild(true, quarantine.GuildID)
+
+	newRoles := make([]string, 0, len(currentRoles)+len(quarantine.RemovedRoles))
+	for _, r := range currentRoles {
+		if r != config.IntQuarantineRole() {
+			newRoles = append(newRoles, strconv.FormatInt(r, 10))
+		}
+	}
+
+	for _, r := range quarantine.RemovedRoles {
+		if common.ContainsInt64Slice(currentRoles, r) {
+			continue
+		}
+
+		// skip roles that were deleted in the meantime
+		if gs != nil && gs.RoleCopy(true, r) == nil {
+			continue
+		}
+
+		newRoles = append(newRoles, strconv.FormatInt(r, 10))
+	}
+
+	err := common.BotSession.GuildMemberEdit(quarantine.GuildID, quarantine.UserID, newRoles)
+	if err != nil {
+		return err
+	}
+
+	return common.GORM.Delete(quarantine).Error
+}
+
+func clearScheduledUnquarantine(guildID, userID int64) error {
+	_, err := seventsmodels.ScheduledEvents(qm.Where("event_name='moderation_unquarantine' AND guild_id = ? AND (data->>'user_id')::bigint = ? AND processed = false", guildID, userID)).DeleteAll(context.Background(), common.PQ)
+	return err
+}
+
+func handleScheduledUnquarantine(evt *seventsmodels.ScheduledEvent, data interface{}) (retry bool, err error) {
+	unquarantineData := data.(*ScheduledUnquarantineData)
+
+	target := &discordgo.User{ID: unquarantineData.UserID, Username: "unknown", Discriminator: "????"}
+	_, err = UnquarantineMember(nil, evt.GuildID, common.BotUser, "Quarantine duration expired", target)
+	if err != nil {
+		return scheduledevents2.CheckDiscordErrRetry(err), err
+	}
+
+	return false, nil
+}
+
+// HandleQuarantineMemberJoin puts quarantined members back in quarantine when they rejoin,
+// or restores their roles if they were released while gone
+func HandleQuarantineMemberJoin(evt *eventsystem.EventData) {
+	c := evt.GuildMemberAdd()
+
+	var quarantine QuarantinedMember
+	err := common.GORM.Where("guild_id = ? AND user_id = ?", c.GuildID, c.User.ID).First(&quarantine).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logrus.WithError(err).WithField("guild", c.GuildID).Error("Failed retrieving quarantine")
+		}
+		return
+	}
+
+	config, err := GetConfig(c.GuildID)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", c.GuildID).Error("Failed retrieving config")
+		return
+	}
+
+	if quarantine.PendingRestore {
+		logrus.WithField("guild", c.GuildID).WithField("user", c.User.ID).Info("Restoring roles after quarantined member rejoined")
+		err = restoreQuarantinedRoles(config, &quarantine, c.Roles)
+	} else if config.IntQuarantineRole() != 0 {
+		logrus.WithField("guild", c.GuildID).WithField("user", c.User.ID).Info("Assigning back quarantine role after member rejoined")
+		err = common.BotSession.GuildMemberRoleAdd(c.GuildID, c.User.ID, config.IntQuarantineRole())
+	}
+
+	if err != nil {
+		logrus.WithError(err).WithField("guild", c.GuildID).Error("Failed updating roles of quarantined member")
+	}
+}
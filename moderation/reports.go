@@ -0,0 +1,457 @@
+package moderation
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/jonas747/dcmd"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/bot"
+	"github.com/jonas747/yagpdb/bot/eventsystem"
+	"github.com/jonas747/yagpdb/commands"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ReportStatus int
+
+const (
+	ReportOpen ReportStatus = iota
+	ReportClaimed
+	ReportResolved
+	ReportDismissed
+)
+
+func (s ReportStatus) String() string {
+	switch s {
+	case ReportOpen:
+		return "Open"
+	case ReportClaimed:
+		return "Claimed"
+	case ReportResolved:
+		return "Resolved"
+	case ReportDismissed:
+		return "Dismissed"
+	}
+
+	return "Unknown"
+}
+
+func (s ReportStatus) Color() int {
+	switch s {
+	case ReportClaimed:
+		return 0xfca253
+	case ReportResolved:
+		return 0x62c65f
+	case ReportDismissed:
+		return 0x57728e
+	}
+
+	return 0xd64848
+}
+
+// Closed returns true if no further action can be taken on the report
+func (s ReportStatus) Closed() bool {
+	return s == ReportResolved || s == ReportDismissed
+}
+
+const (
+	ReportClaimEmoji   = "🙋"
+	ReportResolveEmoji = "✅"
+	ReportDismissEmoji = "🗑"
+
+	// Needed in the report channel to triage reports
+	ReportTriagePerm = discordgo.PermissionManageMessages
+)
+
+// Report is a report made through the report command
+type Report struct {
+	common.SmallModel
+
+	GuildID      int64 `gorm:"unique_index:idx_moderation_reports_guild_number"`
+	ReportNumber int64 `gorm:"unique_index:idx_moderation_reports_guild_number"`
+
+	ReporterID              int64
+	ReporterUsernameDiscrim string
+
+	UserID          int64 `gorm:"index"`
+	UsernameDiscrim string
+
+	ChannelID   int64
+	MessageLink string // optional, the reported message
+	LogsLink    string
+	Reason      string
+
+	Status                 ReportStatus `gorm:"index"`
+	HandlerID              int64
+	HandlerUsernameDiscrim string
+	Note                   string // set by the handler when resolving or dismissing
+
+	// The message in the report channel
+	ReportChannelID int64
+	ReportMessageID int64 `gorm:"index"`
+}
+
+func (r *Report) TableName() string {
+	return "moderation_reports"
+}
+
+var messageLinkRegex = regexp.MustCompile(`^https?://(?:\w+\.)?discord(?:app)?\.com/channels/(\d+)/(\d+)/(\d+)$`)
+
+var cmdReport = &commands.YAGCommand{
+	CustomEnabled: true,
+	Cooldown:      5,
+	CmdCategory:   commands.CategoryModeration,
+	Name:          "Report",
+	Description:   "Reports a member to the server's staff",
+	RequiredArgs:  2,
+	Arguments: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Name: "User", Type: dcmd.UserID},
+		&dcmd.ArgDef{Name: "Reason", Type: dcmd.String},
+	},
+	ArgSwitches: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Switch: "m", Name: "Link or ID of the reported message", Type: dcmd.String},
+	},
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		config, _, err := MBaseCmd(parsed, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = MBaseCmdSecond(parsed, "", true, 0, nil, config.ReportEnabled)
+		if err != nil {
+			return nil, err
+		}
+
+		channelID := config.IntReportChannel()
+		if channelID == 0 {
+			return "No report channel set up", nil
+		}
+
+		messageLink := ""
+		if parsed.Switches["m"].Value != nil {
+			messageLink, err = reportMessageLink(parsed.GS.ID, parsed.CS.ID, parsed.Switches["m"].Str())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		target, _ := bot.GetMember(parsed.GS.ID, parsed.Args[0].Int64())
+		targetUser := &discordgo.User{ID: parsed.Args[0].Int64(), Username: "unknown", Discriminator: "????"}
+		if target != nil {
+			targetUser = target.DGoUser()
+		}
+
+		logLink := CreateLogs(parsed.GS.ID, parsed.CS.ID, parsed.Msg.Author)
+
+		report, err := CreateReport(config, parsed.GS.ID, parsed.CS.ID, parsed.Msg.Author, targetUser, parsed.Args[1].Str(), messageLink, logLink)
+		if err != nil {
+			return nil, err
+		}
+
+		// don't bother sending confirmation if it's in the same channel
+		if report.ReportChannelID != parsed.Msg.ChannelID {
+			return "User reported to the proper authorities", nil
+		}
+		return nil, nil
+	},
+}
+
+var cmdReports = &commands.YAGCommand{
+	CustomEnabled: true,
+	CmdCategory:   commands.CategoryModeration,
+	Name:          "Reports",
+	Description:   "Lists open and claimed reports",
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		config, _, err := MBaseCmd(parsed, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = MBaseCmdSecond(parsed, "", true, ReportTriagePerm, nil, config.ReportEnabled)
+		if err != nil {
+			return nil, err
+		}
+
+		var reports []*Report
+		err = common.GORM.Where("guild_id = ? AND status IN (?)", parsed.GS.ID, []ReportStatus{ReportOpen, ReportClaimed}).Order("report_number asc").Limit(25).Find(&reports).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if len(reports) < 1 {
+			return "No open reports", nil
+		}
+
+		var out strings.Builder
+		for _, v := range reports {
+			line := fmt.Sprintf("`#%d` %s: **%s** reported by %s: %s", v.ReportNumber, v.Status, v.UsernameDiscrim, v.ReporterUsernameDiscrim, common.CutStringShort(v.Reason, 100))
+			if v.Status == ReportClaimed {
+				line += " (claimed by " + v.HandlerUsernameDiscrim + ")"
+			}
+
+			out.WriteString(line + "\n")
+		}
+
+		return out.String(), nil
+	},
+}
+
+func reportTriageCommand(name, description string, status ReportStatus) *commands.YAGCommand {
+	return &commands.YAGCommand{
+		CustomEnabled: true,
+		CmdCategory:   commands.CategoryModeration,
+		Name:          name,
+		Description:   description,
+		RequiredArgs:  1,
+		Arguments: []*dcmd.ArgDef{
+			&dcmd.ArgDef{Name: "Report", Type: dcmd.Int},
+			&dcmd.ArgDef{Name: "Note", Type: dcmd.String},
+		},
+		RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+			config, _, err := MBaseCmd(parsed, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = MBaseCmdSecond(parsed, "", true, ReportTriagePerm, nil, config.ReportEnabled)
+			if err != nil {
+				return nil, err
+			}
+
+			var report Report
+			err = common.GORM.Where("guild_id = ? AND report_number = ?", parsed.GS.ID, parsed.Args[0].Int64()).First(&report).Error
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return "Unknown report", nil
+				}
+
+				return nil, err
+			}
+
+			ok, err := UpdateReportStatus(config, &report, parsed.Msg.Author, status, SafeArgString(parsed, 1))
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				return fmt.Sprintf("Report #%d is already %s", report.ReportNumber, strings.ToLower(report.Status.String())), nil
+			}
+
+			return fmt.Sprintf("Report #%d is now %s", report.ReportNumber, strings.ToLower(status.String())), nil
+		},
+	}
+}
+
+var (
+	cmdClaimReport   = reportTriageCommand("ClaimReport", "Claims a report, showing that you're handling it", ReportClaimed)
+	cmdResolveReport = reportTriageCommand("ResolveReport", "Marks a report as resolved, with a optional note", ReportResolved)
+	cmdDismissReport = reportTriageCommand("DismissReport", "Dismisses a report, with a optional note", ReportDismissed)
+)
+
+// reportMessageLink returns a link to the message specified either by a full message link or a message ID in the current channel
+func reportMessageLink(guildID, channelID int64, str string) (string, error) {
+	str = strings.Trim(strings.TrimSpace(str), "<>")
+
+	if m := messageLinkRegex.FindStringSubmatch(str); m != nil {
+		if m[1] != discordgo.StrID(guildID) {
+			return "", commands.NewPublicError("That message is not on this server")
+		}
+
+		return str, nil
+	}
+
+	messageID, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return "", commands.NewPublicError("Invalid message, specify either a message link or the ID of a message in this channel")
+	}
+
+	return fmt.Sprintf("https://discordapp.com/channels/%d/%d/%d", guildID, channelID, messageID), nil
+}
+
+// CreateReport stores the report and posts it in the report channel
+func CreateReport(config *Config, guildID, channelID int64, reporter, target *discordgo.User, reason, messageLink, logLink string) (*Report, error) {
+	reportNumber, err := common.GenLocalIncrID(guildID, "moderation_reports")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		GuildID:                 guildID,
+		ReportNumber:            reportNumber,
+		ReporterID:              reporter.ID,
+		ReporterUsernameDiscrim: reporter.Username + "#" + reporter.Discriminator,
+		UserID:                  target.ID,
+		UsernameDiscrim:         target.Username + "#" + target.Discriminator,
+		ChannelID:               channelID,
+		MessageLink:             messageLink,
+		LogsLink:                logLink,
+		Reason:                  reason,
+		ReportChannelID:         config.IntReportChannel(),
+	}
+
+	err = common.GORM.Create(report).Error
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := common.BotSession.ChannelMessageSendEmbed(report.ReportChannelID, ReportEmbed(report))
+	if err != nil {
+		return report, err
+	}
+
+	report.ReportMessageID = msg.ID
+	err = common.GORM.Model(report).Update("report_message_id", msg.ID).Error
+	if err != nil {
+		return report, err
+	}
+
+	for _, emoji := range []string{ReportClaimEmoji, ReportResolveEmoji, ReportDismissEmoji} {
+		err = common.BotSession.MessageReactionAdd(report.ReportChannelID, msg.ID, emoji)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// ReportEmbed returns the embed for the report as shown in the report channel
+func ReportEmbed(report *Report) *discordgo.MessageEmbed {
+	desc := fmt.Sprintf("<@%d> Reported <@%d> in <#%d> For `%s`", report.ReporterID, report.UserID, report.ChannelID, report.Reason)
+	if report.MessageLink != "" {
+		desc += fmt.Sprintf("\nReported message: [Jump](%s)", report.MessageLink)
+	}
+
+	if report.LogsLink != "" {
+		desc += fmt.Sprintf("\nLast 100 messages from channel: <%s>", report.LogsLink)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Report #%d: %s (ID %d)", report.ReportNumber, report.UsernameDiscrim, report.UserID),
+		Description: common.CutStringShort(desc, 2000),
+		Color:       report.Status.Color(),
+		Fields: []*discordgo.MessageEmbedField{
+			&discordgo.MessageEmbedField{Name: "Status", Value: report.Status.String(), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%s claim, %s resolve, %s dismiss", ReportClaimEmoji, ReportResolveEmoji, ReportDismissEmoji),
+		},
+		Timestamp: report.CreatedAt.Format(time.RFC3339),
+	}
+
+	if report.HandlerID != 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Handled by", Value: report.HandlerUsernameDiscrim, Inline: true})
+	}
+
+	if report.Note != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Note", Value: common.CutStringShort(report.Note, 1000)})
+	}
+
+	return embed
+}
+
+// UpdateReportStatus changes the status of the report, updating the report message and notifying the reporter if enabled.
+// Returns false if the report was already closed, or already in the status
+func UpdateReportStatus(config *Config, report *Report, handler *discordgo.User, status ReportStatus, note string) (bool, error) {
+	if report.Status.Closed() || report.Status == status {
+		return false, nil
+	}
+
+	updates := map[string]interface{}{
+		"status":                   status,
+		"handler_id":               handler.ID,
+		"handler_username_discrim": handler.Username + "#" + handler.Discriminator,
+	}
+	if note != "" {
+		updates["note"] = note
+	}
+
+	// guard against concurrent updates, e.g multiple moderators reacting at the same time
+	result := common.GORM.Model(report).Where("status = ?", report.Status).Updates(updates)
+	if result.Error != nil || result.RowsAffected < 1 {
+		return false, result.Error
+	}
+
+	report.Status = status
+	report.HandlerID = handler.ID
+	report.HandlerUsernameDiscrim = handler.Username + "#" + handler.Discriminator
+	if note != "" {
+		report.Note = note
+	}
+
+	if report.ReportMessageID != 0 {
+		_, err := common.BotSession.ChannelMessageEditEmbed(report.ReportChannelID, report.ReportMessageID, ReportEmbed(report))
+		if err != nil && !common.IsDiscordErr(err, discordgo.ErrCodeUnknownMessage, discordgo.ErrCodeUnknownChannel) {
+			return true, err
+		}
+	}
+
+	if status.Closed() && config.ReportDMOnResolve {
+		msg := fmt.Sprintf("Your report #%d of %s has been %s", report.ReportNumber, report.UsernameDiscrim, strings.ToLower(status.String()))
+		if report.Note != "" {
+			msg += ": " + report.Note
+		}
+
+		go bot.SendDM(report.ReporterID, "**"+bot.GuildName(report.GuildID)+":** "+common.EscapeSpecialMentions(msg))
+	}
+
+	return true, nil
+}
+
+// HandleReportReaction triages reports when staff reacts to them in the report channel
+func HandleReportReaction(evt *eventsystem.EventData) {
+	ra := evt.MessageReactionAdd()
+	if ra.GuildID == 0 || ra.UserID == common.BotUser.ID || ra.Emoji.ID != 0 {
+		return
+	}
+
+	var status ReportStatus
+	switch ra.Emoji.Name {
+	case ReportClaimEmoji:
+		status = ReportClaimed
+	case ReportResolveEmoji:
+		status = ReportResolved
+	case ReportDismissEmoji:
+		status = ReportDismissed
+	default:
+		return
+	}
+
+	config, err := GetConfig(ra.GuildID)
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed retrieving config")
+		return
+	}
+
+	// reports are only posted in the report channel, avoid looking up every reaction in the database
+	if ra.ChannelID != config.IntReportChannel() {
+		return
+	}
+
+	var report Report
+	err = common.GORM.Where("guild_id = ? AND report_channel_id = ? AND report_message_id = ?", ra.GuildID, ra.ChannelID, ra.MessageID).First(&report).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed retrieving report")
+		}
+		return
+	}
+
+	ms, err := bot.GetMember(ra.GuildID, ra.UserID)
+	if err != nil || ms == nil {
+		return
+	}
+
+	if hasPerms, _ := bot.AdminOrPermMS(ms, ra.ChannelID, ReportTriagePerm); !hasPerms {
+		return
+	}
+
+	_, err = UpdateReportStatus(config, &report, ms.DGoUser(), status, "")
+	if err != nil {
+		logrus.WithError(err).WithField("guild", ra.GuildID).Error("failed updating report status")
+	}
+}
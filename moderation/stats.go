@@ -0,0 +1,231 @@
+package moderation
+
+import (
+	"fmt"
+	"github.com/jonas747/dcmd"
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/commands"
+	"github.com/jonas747/yagpdb/common"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CleanLogEntry records a use of the clean command, since cleans are not tied to a user they don't get a case
+type CleanLogEntry struct {
+	common.SmallModel
+	GuildID  int64 `gorm:"index"`
+	AuthorID int64
+
+	AuthorUsernameDiscrim string
+	ChannelID             int64
+	NumDeleted            int
+}
+
+func (c *CleanLogEntry) TableName() string {
+	return "moderation_clean_logs"
+}
+
+// ModeratorStats is the number of actions performed by a single moderator
+type ModeratorStats struct {
+	AuthorID              int64
+	AuthorUsernameDiscrim string
+
+	Bans    int
+	Unbans  int
+	Kicks   int
+	Mutes   int
+	Warns   int
+	Cleans  int
+	Other   int
+	Deleted int // messages deleted through cleans
+}
+
+func (s *ModeratorStats) Total() int {
+	return s.Bans + s.Unbans + s.Kicks + s.Mutes + s.Warns + s.Cleans + s.Other
+}
+
+// The periods available in the stats command and on the stats page, in days, 0 is all time
+var ModStatsPeriods = []int{1, 7, 30, 90, 0}
+
+func logClean(guildID, channelID int64, author *discordgo.User, numDeleted int) {
+	err := common.GORM.Create(&CleanLogEntry{
+		GuildID:               guildID,
+		AuthorID:              author.ID,
+		AuthorUsernameDiscrim: author.Username + "#" + author.Discriminator,
+		ChannelID:             channelID,
+		NumDeleted:            numDeleted,
+	}).Error
+	if err != nil {
+		logrus.WithError(err).WithField("guild", guildID).Error("failed logging clean")
+	}
+}
+
+// GetModeratorStats returns the number of actions per moderator since the specified time (zero time for all time),
+// sorted by the total number of actions. If authorID is not 0 only that moderator is included
+func GetModeratorStats(guildID int64, since time.Time, authorID int64) ([]*ModeratorStats, error) {
+	stats := make(map[int64]*ModeratorStats)
+	getStats := func(id int64, usernameDiscrim string) *ModeratorStats {
+		if s, ok := stats[id]; ok {
+			return s
+		}
+
+		s := &ModeratorStats{AuthorID: id, AuthorUsernameDiscrim: usernameDiscrim}
+		stats[id] = s
+		return s
+	}
+
+	// cases without a author are not attributed to anyone, e.g when the audit log is not available
+	query := common.GORM.Model(&ModerationCase{}).Select("author_id, max(author_username_discrim), action, count(*)").
+		Where("guild_id = ? AND author_id != 0 AND created_at > ?", guildID, since)
+	if authorID != 0 {
+		query = query.Where("author_id = ?", authorID)
+	}
+
+	rows, err := query.Group("author_id, action").Rows()
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var id int64
+		var usernameDiscrim, action string
+		var count int
+		err = rows.Scan(&id, &usernameDiscrim, &action, &count)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		s := getStats(id, usernameDiscrim)
+		switch action {
+		case MABanned.Prefix:
+			s.Bans += count
+		case MAUnbanned.Prefix:
+			s.Unbans += count
+		case MAKick.Prefix:
+			s.Kicks += count
+		case MAMute.Prefix:
+			s.Mutes += count
+		case MAWarned.Prefix:
+			s.Warns += count
+		default:
+			s.Other += count
+		}
+	}
+	rows.Close()
+
+	query = common.GORM.Model(&CleanLogEntry{}).Select("author_id, max(author_username_discrim), count(*), sum(num_deleted)").
+		Where("guild_id = ? AND created_at > ?", guildID, since)
+	if authorID != 0 {
+		query = query.Where("author_id = ?", authorID)
+	}
+
+	rows, err = query.Group("author_id").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var usernameDiscrim string
+		var count, deleted int
+		err = rows.Scan(&id, &usernameDiscrim, &count, &deleted)
+		if err != nil {
+			return nil, err
+		}
+
+		s := getStats(id, usernameDiscrim)
+		s.Cleans += count
+		s.Deleted += deleted
+	}
+
+	result := make([]*ModeratorStats, 0, len(stats))
+	for _, v := range stats {
+		result = append(result, v)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Total() > result[j].Total()
+	})
+
+	return result, nil
+}
+
+func modStatsSince(days int) time.Time {
+	if days <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(-time.Hour * 24 * time.Duration(days))
+}
+
+func modStatsPeriodName(days int) string {
+	if days <= 0 {
+		return "all time"
+	}
+
+	if days == 1 {
+		return "the last day"
+	}
+
+	return fmt.Sprintf("the last %d days", days)
+}
+
+var cmdModStats = &commands.YAGCommand{
+	CustomEnabled:   true,
+	CmdCategory:     commands.CategoryModeration,
+	Name:            "ModStats",
+	Description:     "Shows the number of moderation actions per moderator, or for the specified moderator",
+	LongDescription: "Specify the period in days with -days, 0 for all time. Defaults to 30 days.",
+	Arguments: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Name: "User", Type: dcmd.UserID},
+	},
+	ArgSwitches: []*dcmd.ArgDef{
+		&dcmd.ArgDef{Switch: "days", Name: "Days", Default: 30, Type: &dcmd.IntArg{Min: 0, Max: 3650}},
+	},
+	RunFunc: func(parsed *dcmd.Data) (interface{}, error) {
+		_, _, err := MBaseCmd(parsed, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = MBaseCmdSecond(parsed, "", true, discordgo.PermissionManageMessages, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var authorID int64
+		if parsed.Args[0].Value != nil {
+			authorID = parsed.Args[0].Int64()
+		}
+
+		days := parsed.Switches["days"].Int()
+		stats, err := GetModeratorStats(parsed.GS.ID, modStatsSince(days), authorID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(stats) < 1 {
+			return "No moderation actions in " + modStatsPeriodName(days), nil
+		}
+
+		var out strings.Builder
+		out.WriteString(fmt.Sprintf("Moderation actions in %s:\n```\n", modStatsPeriodName(days)))
+		out.WriteString(fmt.Sprintf("%-20s %5s %5s %5s %5s %5s %6s %5s\n", "Moderator", "Bans", "Unban", "Kicks", "Mutes", "Warns", "Cleans", "Other"))
+		for i, v := range stats {
+			if i >= 15 {
+				out.WriteString(fmt.Sprintf("...and %d more\n", len(stats)-i))
+				break
+			}
+
+			out.WriteString(fmt.Sprintf("%-20s %5d %5d %5d %5d %5d %6d %5d\n", common.CutStringShort(v.AuthorUsernameDiscrim, 20),
+				v.Bans, v.Unbans, v.Kicks, v.Mutes, v.Warns, v.Cleans, v.Other))
+		}
+		out.WriteString("```")
+
+		return out.String(), nil
+	},
+}
@@ -0,0 +1,191 @@
+package web
+
+import (
+	"github.com/jonas747/discordgo"
+	"github.com/jonas747/yagpdb/common"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ExportedEntity is a role or channel referenced by a export, the name is used to help remapping it when imported on another server
+type ExportedEntity struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// ExportedRefs holds the roles and channels referenced by a export, plugins embed it in their export formats
+type ExportedRefs struct {
+	Roles    []*ExportedEntity `json:"roles"`
+	Channels []*ExportedEntity `json:"channels"`
+}
+
+// NewExportedRefs creates the refs for the roles and channels, looking up their names on the guild
+func NewExportedRefs(g *discordgo.Guild, roleIDs, channelIDs []int64) ExportedRefs {
+	var refs ExportedRefs
+
+	for _, v := range roleIDs {
+		entity := &ExportedEntity{ID: v}
+		if r := g.Role(v); r != nil {
+			entity.Name = r.Name
+		}
+		refs.Roles = append(refs.Roles, entity)
+	}
+
+	for _, v := range channelIDs {
+		entity := &ExportedEntity{ID: v}
+		if c := g.Channel(v); c != nil {
+			entity.Name = c.Name
+		}
+		refs.Channels = append(refs.Channels, entity)
+	}
+
+	return refs
+}
+
+// ForeignRefs returns the roles and channels referenced that does not exist on the guild, these need to be remapped
+func (e *ExportedRefs) ForeignRefs(g *discordgo.Guild) (roles []*ExportedEntity, channels []*ExportedEntity) {
+	for _, v := range e.Roles {
+		if g.Role(v.ID) == nil {
+			roles = append(roles, v)
+		}
+	}
+
+	for _, v := range e.Channels {
+		if g.Channel(v.ID) == nil {
+			channels = append(channels, v)
+		}
+	}
+
+	return
+}
+
+// ImportMapping is a role or channel referenced by a import that does not exist on this server and needs to be remapped
+type ImportMapping struct {
+	Old       *ExportedEntity
+	Suggested int64
+}
+
+// ImportRemap remaps the roles and channels referenced by a import that does not exist on the guild,
+// using the old id -> new id mappings chosen by the user (0 to remove it)
+type ImportRemap struct {
+	Guild      *discordgo.Guild
+	RoleMap    map[int64]int64
+	ChannelMap map[int64]int64
+}
+
+// ReadImportRemap reads the mappings for the foreign roles and channels of the refs from the form (RoleMap.<id> and ChannelMap.<id>).
+// If one or more of them has not been mapped yet, remap is nil and the mappings to ask the user for are returned,
+// with a suggestion for each of them based on the name
+func ReadImportRemap(form url.Values, g *discordgo.Guild, refs *ExportedRefs) (remap *ImportRemap, roles, channels []*ImportMapping) {
+	foreignRoles, foreignChannels := refs.ForeignRefs(g)
+
+	// The form parsing utility dosen't take care of maps, so manually read the mappings
+	roleMap, rolesMapped := readImportMappings(form, "RoleMap", foreignRoles)
+	channelMap, channelsMapped := readImportMappings(form, "ChannelMap", foreignChannels)
+
+	if rolesMapped && channelsMapped {
+		return &ImportRemap{Guild: g, RoleMap: roleMap, ChannelMap: channelMap}, nil, nil
+	}
+
+	roles = make([]*ImportMapping, 0, len(foreignRoles))
+	for _, v := range foreignRoles {
+		m := &ImportMapping{Old: v}
+		for _, role := range g.Roles {
+			if strings.EqualFold(role.Name, v.Name) {
+				m.Suggested = role.ID
+				break
+			}
+		}
+		roles = append(roles, m)
+	}
+
+	channels = make([]*ImportMapping, 0, len(foreignChannels))
+	for _, v := range foreignChannels {
+		m := &ImportMapping{Old: v}
+		for _, channel := range g.Channels {
+			if strings.EqualFold(channel.Name, v.Name) {
+				m.Suggested = channel.ID
+				break
+			}
+		}
+		channels = append(channels, m)
+	}
+
+	return nil, roles, channels
+}
+
+// readImportMappings reads the old id -> new id mappings for the entities from the form, allMapped is false if one or more are missing
+func readImportMappings(form url.Values, prefix string, entities []*ExportedEntity) (result map[int64]int64, allMapped bool) {
+	result = make(map[int64]int64)
+	allMapped = true
+
+	for _, v := range entities {
+		key := prefix + "." + strconv.FormatInt(v.ID, 10)
+		if _, ok := form[key]; !ok {
+			allMapped = false
+			continue
+		}
+
+		result[v.ID], _ = strconv.ParseInt(form.Get(key), 10, 64)
+	}
+
+	return
+}
+
+func (r *ImportRemap) roleExists(id int64) bool {
+	return r.Guild.Role(id) != nil
+}
+
+func (r *ImportRemap) channelExists(id int64) bool {
+	return r.Guild.Channel(id) != nil
+}
+
+// Role returns the role to use in place of id, 0 if it should be removed
+func (r *ImportRemap) Role(id int64) int64 {
+	return remapID(id, r.roleExists, r.RoleMap)
+}
+
+// Channel returns the channel to use in place of id, 0 if it should be removed
+func (r *ImportRemap) Channel(id int64) int64 {
+	return remapID(id, r.channelExists, r.ChannelMap)
+}
+
+// Roles returns the roles to use in place of ids, with removed and duplicate roles left out
+func (r *ImportRemap) Roles(ids []int64) []int64 {
+	return remapIDs(ids, r.roleExists, r.RoleMap)
+}
+
+// Channels returns the channels to use in place of ids, with removed and duplicate channels left out
+func (r *ImportRemap) Channels(ids []int64) []int64 {
+	return remapIDs(ids, r.channelExists, r.ChannelMap)
+}
+
+func remapID(id int64, exists func(int64) bool, mapping map[int64]int64) int64 {
+	if exists(id) {
+		return id
+	}
+
+	if newID := mapping[id]; exists(newID) {
+		return newID
+	}
+
+	return 0
+}
+
+// remapIDs returns the ids with the ones that don't exist replaced using the map (old id -> new id, 0 to remove it)
+func remapIDs(ids []int64, exists func(int64) bool, mapping map[int64]int64) []int64 {
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		id = remapID(id, exists, mapping)
+		if id == 0 {
+			continue
+		}
+
+		if !common.ContainsInt64Slice(result, id) {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
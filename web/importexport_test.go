@@ -0,0 +1,34 @@
+package web
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemapIDs(t *testing.T) {
+	exists := func(id int64) bool { return id == 1 || id == 2 || id == 3 }
+	mapping := map[int64]int64{
+		10: 2,  // remapped
+		11: 0,  // removed
+		12: 99, // remapped to something that does not exist either
+		13: 3,  // remapped to something already in the result
+	}
+
+	cases := []struct {
+		ids      []int64
+		expected []int64
+	}{
+		{nil, []int64{}},
+		{[]int64{1, 2}, []int64{1, 2}},
+		{[]int64{1, 10}, []int64{1, 2}},
+		{[]int64{11, 12, 14}, []int64{}},
+		{[]int64{3, 13, 1}, []int64{3, 1}},
+	}
+
+	for i, c := range cases {
+		result := remapIDs(c.ids, exists, mapping)
+		if !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("case %d: got %v, expected %v", i, result, c.expected)
+		}
+	}
+}